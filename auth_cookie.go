@@ -0,0 +1,141 @@
+package bosbase
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultAuthCookieName is the cookie name used by the JS SDK.
+const DefaultAuthCookieName = "pb_auth"
+
+// maxCookieSize is the browser limit for a single cookie.
+const maxCookieSize = 4096
+
+// CookieOptions configures ExportToCookie. A nil *CookieOptions exports a
+// Secure, HttpOnly, SameSite=Strict cookie named DefaultAuthCookieName on
+// path "/"; with non-nil options the flags are used as given, while an empty
+// Name, Path or SameSite falls back to those defaults.
+type CookieOptions struct {
+	Name     string
+	Path     string
+	Domain   string
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+}
+
+// ExportToCookie is ExportAuthCookie for s.
+func (s *AuthStore) ExportToCookie(opts *CookieOptions) *http.Cookie {
+	return ExportAuthCookie(s, opts)
+}
+
+// LoadFromCookie is LoadAuthCookie for s.
+func (s *AuthStore) LoadFromCookie(r *http.Request, name string) error {
+	return LoadAuthCookie(s, r, name)
+}
+
+// LoadFromCookie is like AuthStore.LoadFromCookie but also persists the
+// loaded state to the file.
+func (s *FileAuthStore) LoadFromCookie(r *http.Request, name string) error {
+	return LoadAuthCookie(s, r, name)
+}
+
+// ExportAuthCookie serializes the auth state of store into a cookie
+// compatible with the JS SDK's exportToCookie, e.g. for client.AuthStore.
+// MaxAge and Expires follow the token exp; an empty store yields an expired
+// cookie that clears the browser state. When the serialized record would
+// exceed the 4KB cookie limit, only its id, email, collectionId,
+// collectionName and verified fields are kept.
+func ExportAuthCookie(store AuthStorer, opts *CookieOptions) *http.Cookie {
+	options := CookieOptions{Secure: true, HttpOnly: true}
+	if opts != nil {
+		options = *opts
+	}
+	if options.Name == "" {
+		options.Name = DefaultAuthCookieName
+	}
+	if options.Path == "" {
+		options.Path = "/"
+	}
+	if options.SameSite == 0 {
+		options.SameSite = http.SameSiteStrictMode
+	}
+
+	cookie := &http.Cookie{
+		Name:     options.Name,
+		Path:     options.Path,
+		Domain:   options.Domain,
+		Secure:   options.Secure,
+		HttpOnly: options.HttpOnly,
+		SameSite: options.SameSite,
+	}
+
+	token := store.Token()
+	exp, ok := tokenExpiry(token)
+	if token == "" || !ok || !exp.After(time.Now()) {
+		cookie.MaxAge = -1
+		cookie.Expires = time.Unix(0, 0)
+		return cookie
+	}
+	cookie.Expires = exp
+	cookie.MaxAge = int(time.Until(exp).Seconds())
+	if cookie.MaxAge <= 0 {
+		cookie.MaxAge = 1
+	}
+
+	record := store.Record()
+	cookie.Value = encodeAuthCookie(token, record)
+	if len(cookie.String()) > maxCookieSize && record != nil {
+		reduced := map[string]interface{}{}
+		for _, key := range []string{"id", "email", "collectionId", "collectionName", "verified"} {
+			if v, ok := record[key]; ok {
+				reduced[key] = v
+			}
+		}
+		cookie.Value = encodeAuthCookie(token, reduced)
+	}
+	return cookie
+}
+
+func encodeAuthCookie(token string, record map[string]interface{}) string {
+	raw, _ := json.Marshal(map[string]interface{}{"token": token, "record": record})
+	// like encodeURIComponent, encode spaces as %20 rather than +; the few
+	// extra characters QueryEscape encodes are decoded by the JS SDK as well
+	return strings.ReplaceAll(url.QueryEscape(string(raw)), "+", "%20")
+}
+
+// LoadAuthCookie loads the auth state of store from the named cookie of r
+// (an empty name means DefaultAuthCookieName), e.g. for client.AuthStore. A
+// missing cookie clears the store.
+func LoadAuthCookie(store AuthStorer, r *http.Request, name string) error {
+	if r == nil {
+		return errors.New("request must not be nil")
+	}
+	if name == "" {
+		name = DefaultAuthCookieName
+	}
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		store.Clear()
+		return nil
+	}
+	raw, err := url.PathUnescape(cookie.Value)
+	if err != nil {
+		store.Clear()
+		return err
+	}
+	var state struct {
+		Token  string                 `json:"token"`
+		Record map[string]interface{} `json:"record"`
+	}
+	if err := json.Unmarshal([]byte(raw), &state); err != nil {
+		store.Clear()
+		return err
+	}
+	store.Save(state.Token, state.Record)
+	return nil
+}
@@ -0,0 +1,65 @@
+package bosbase
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testToken(exp time.Time, extra string) string {
+	payload := fmt.Sprintf(`{"id":"u1","collectionId":"c1","type":"auth","exp":%d%s}`, exp.Unix(), extra)
+	return "header." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".sig"
+}
+
+func TestAuthCookieRoundTrip(t *testing.T) {
+	token := testToken(time.Now().Add(time.Hour), "")
+	record := map[string]interface{}{"id": "u1", "name": "Jane Doe", "bio": "a+b=c & d:@$/?#"}
+
+	var store AuthStorer = NewAuthStore()
+	store.Save(token, record)
+	cookie := ExportAuthCookie(store, nil)
+
+	if cookie.Name != DefaultAuthCookieName || !cookie.Secure || !cookie.HttpOnly || cookie.SameSite != http.SameSiteStrictMode {
+		t.Errorf("unexpected cookie attributes: %s", cookie)
+	}
+	if cookie.MaxAge <= 0 || cookie.MaxAge > 3600 {
+		t.Errorf("MaxAge = %d", cookie.MaxAge)
+	}
+	// encodeURIComponent leaves none of these unescaped
+	if strings.ContainsAny(cookie.Value, " +:@&=$/?#") {
+		t.Errorf("cookie value is not fully escaped: %s", cookie.Value)
+	}
+	if !strings.Contains(cookie.Value, "Jane%20Doe") {
+		t.Errorf("spaces should be encoded as %%20: %s", cookie.Value)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookie)
+	loaded := NewAuthStore()
+	if err := LoadAuthCookie(loaded, req, ""); err != nil {
+		t.Fatalf("LoadAuthCookie() error = %v", err)
+	}
+	if loaded.Token() != token || loaded.Record()["bio"] != record["bio"] || loaded.Record()["name"] != "Jane Doe" {
+		t.Errorf("loaded %q %v", loaded.Token(), loaded.Record())
+	}
+}
+
+func TestAuthCookieEmptyStore(t *testing.T) {
+	cookie := ExportAuthCookie(NewAuthStore(), &CookieOptions{Name: "session"})
+	if cookie.Name != "session" || cookie.MaxAge >= 0 || cookie.Value != "" {
+		t.Errorf("want an expired empty cookie, got %s", cookie)
+	}
+
+	store := NewAuthStore()
+	store.Save(testToken(time.Now().Add(time.Hour), ""), map[string]interface{}{"id": "u1"})
+	if err := LoadAuthCookie(store, httptest.NewRequest(http.MethodGet, "/", nil), ""); err != nil {
+		t.Fatalf("LoadAuthCookie() error = %v", err)
+	}
+	if store.Token() != "" {
+		t.Error("a missing cookie should clear the store")
+	}
+}
@@ -0,0 +1,187 @@
+package bosbase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reauthenticator obtains a fresh token for client, typically by running one
+// of the auth methods (which save the result to the AuthStore).
+type Reauthenticator func(ctx context.Context, client *BosBase) error
+
+// AutoRefreshOptions configures WithAutoRefresh.
+type AutoRefreshOptions struct {
+	// Threshold is how long before expiry the token is refreshed through
+	// auth-refresh (defaults to 5 minutes).
+	Threshold time.Duration
+	// Reauthenticate, when set, is used when the token has already expired,
+	// cannot be refreshed, or a request is rejected with 401. The rejected
+	// request is then retried once with the new token.
+	Reauthenticate Reauthenticator
+}
+
+// PasswordReauthenticator re-runs AuthWithPassword against collection, e.g.
+// "_superusers" for service clients.
+func PasswordReauthenticator(collection, identity, password string) Reauthenticator {
+	return func(ctx context.Context, client *BosBase) error {
+		_, err := client.Collection(collection).AuthWithPasswordCtx(ctx, identity, password, "", "", nil, nil, nil)
+		return err
+	}
+}
+
+// TokenReauthenticator re-runs AuthWithToken with a custom token.
+func TokenReauthenticator(collection, token string) Reauthenticator {
+	return func(ctx context.Context, client *BosBase) error {
+		_, err := client.Collection(collection).AuthWithTokenCtx(ctx, token, "", "", nil, nil, nil)
+		return err
+	}
+}
+
+// WithAutoRefresh keeps the stored token fresh: it is refreshed shortly
+// before expiry and, with a Reauthenticate provider, re-acquired on 401.
+// Concurrent requests share a single refresh in flight.
+func WithAutoRefresh(opts AutoRefreshOptions) ClientOption {
+	return func(c *BosBase) {
+		if opts.Threshold <= 0 {
+			opts.Threshold = 5 * time.Minute
+		}
+		c.autoRefresh = &autoRefresher{opts: opts}
+	}
+}
+
+type autoRefreshKey struct{}
+
+type refreshCall struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+type autoRefresher struct {
+	opts AutoRefreshOptions
+
+	mu       sync.Mutex
+	inflight *refreshCall
+}
+
+// autoRefreshMiddleware runs right before the transport so user middlewares
+// observe a single call even when the request is retried after a 401.
+func (c *BosBase) autoRefreshMiddleware() Middleware {
+	return func(next Handler) Handler {
+		r := c.autoRefresh
+		if r == nil {
+			return next
+		}
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			if ctx.Value(autoRefreshKey{}) != nil || c.AuthStore == nil || isAuthPath(req.Path) {
+				return next(ctx, req)
+			}
+			storeToken := c.AuthStore.Token()
+			header := req.Headers["Authorization"]
+			if header != "" && header != storeToken {
+				// explicit credentials, leave them alone
+				return next(ctx, req)
+			}
+
+			if storeToken != "" {
+				if exp, ok := tokenExpiry(storeToken); ok && time.Until(exp) < r.opts.Threshold {
+					if token, err := r.refresh(ctx, c, storeToken, false); err == nil && token != "" {
+						req.Headers["Authorization"] = token
+					}
+				}
+			}
+
+			resp, err := next(ctx, req)
+			if err == nil || r.opts.Reauthenticate == nil || !IsUnauthorized(err) {
+				return resp, err
+			}
+			offsets, rewindable := fileOffsets(req.Files)
+			if !rewindable {
+				return resp, err
+			}
+			token, reauthErr := r.refresh(ctx, c, req.Headers["Authorization"], true)
+			if reauthErr != nil || token == "" {
+				return resp, err
+			}
+			if rewindErr := rewindFiles(req.Files, offsets); rewindErr != nil {
+				return resp, err
+			}
+			req.Headers["Authorization"] = token
+			return next(ctx, req)
+		}
+	}
+}
+
+// refresh returns a fresh token, sharing the work with concurrent callers.
+// stale is the token the caller found unusable; if the store already holds a
+// different valid token (refreshed by someone else) it is returned as is.
+func (r *autoRefresher) refresh(ctx context.Context, c *BosBase, stale string, rejected bool) (string, error) {
+	r.mu.Lock()
+	if current := c.AuthStore.Token(); current != "" && current != stale && c.AuthStore.IsValid() {
+		r.mu.Unlock()
+		return current, nil
+	}
+	call := r.inflight
+	if call == nil {
+		call = &refreshCall{done: make(chan struct{})}
+		r.inflight = call
+		go func() {
+			call.token, call.err = r.run(c, stale, rejected)
+			r.mu.Lock()
+			r.inflight = nil
+			r.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// run performs the refresh detached from the caller's cancellation, since
+// other callers may be waiting on it.
+func (r *autoRefresher) run(c *BosBase, stale string, rejected bool) (string, error) {
+	ctx := context.WithValue(context.Background(), autoRefreshKey{}, true)
+
+	if !rejected && stale != "" && c.AuthStore.IsValid() {
+		if collection := authCollection(c.AuthStore.Record()); collection != "" {
+			_, err := c.Collection(collection).AuthRefreshCtx(ctx, "", "", nil, nil, map[string]string{"Authorization": stale})
+			if err == nil {
+				return c.AuthStore.Token(), nil
+			}
+			if r.opts.Reauthenticate == nil {
+				return "", err
+			}
+		}
+	}
+	if r.opts.Reauthenticate == nil {
+		return "", errors.New("auto refresh: no way to refresh the auth token")
+	}
+	if err := r.opts.Reauthenticate(ctx, c); err != nil {
+		return "", err
+	}
+	return c.AuthStore.Token(), nil
+}
+
+// isAuthPath reports whether path is an auth endpoint. Their 401 responses
+// (wrong credentials, MFA challenges) must reach the caller untouched instead
+// of triggering Reauthenticate, which may call the same endpoint again.
+func isAuthPath(path string) bool {
+	return strings.Contains(path, "/auth-with-") || strings.HasSuffix(path, "/auth-refresh")
+}
+
+func authCollection(record map[string]interface{}) string {
+	if name, _ := record["collectionName"].(string); name != "" {
+		return name
+	}
+	id, _ := record["collectionId"].(string)
+	return id
+}
@@ -0,0 +1,94 @@
+package bosbase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAutoRefreshSkipsAuthEndpoints(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/auth-with-password"):
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":401,"message":"Failed to authenticate."}`))
+		case r.Header.Get("Authorization") == "fresh":
+			w.Write([]byte(`{"id":"r1"}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":401,"message":"Unauthorized."}`))
+		}
+	}))
+	defer server.Close()
+
+	var reauths int32
+	client := New(server.URL, WithAutoRefresh(AutoRefreshOptions{
+		Reauthenticate: func(ctx context.Context, client *BosBase) error {
+			atomic.AddInt32(&reauths, 1)
+			client.AuthStore.Save("fresh", map[string]interface{}{"id": "u1", "collectionName": "users"})
+			return nil
+		},
+	}))
+
+	// a rejected login must reach the caller as is
+	_, err := client.Collection("users").AuthWithPasswordCtx(context.Background(), "a@b.c", "wrong", "", "", nil, nil, nil)
+	if !IsUnauthorized(err) {
+		t.Fatalf("AuthWithPasswordCtx() error = %v, want 401", err)
+	}
+	if got := atomic.LoadInt32(&reauths); got != 0 {
+		t.Fatalf("Reauthenticate ran %d times for an auth endpoint", got)
+	}
+
+	// other requests are re-authenticated and retried once
+	record, err := client.Collection("posts").GetOneCtx(context.Background(), "r1", nil)
+	if err != nil {
+		t.Fatalf("GetOneCtx() error = %v", err)
+	}
+	if record["id"] != "r1" {
+		t.Errorf("GetOneCtx() = %v", record)
+	}
+	if got := atomic.LoadInt32(&reauths); got != 1 {
+		t.Errorf("Reauthenticate ran %d times, want 1", got)
+	}
+}
+
+func TestImpersonateDisablesAutoRefresh(t *testing.T) {
+	var refreshes int32
+	impersonated := testToken(time.Now().Add(time.Minute), "")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/auth-refresh"):
+			atomic.AddInt32(&refreshes, 1)
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":401,"message":"Unauthorized."}`))
+		case strings.Contains(r.URL.Path, "/impersonate/"):
+			fmt.Fprintf(w, `{"token":%q,"record":{"id":"u2","collectionName":"users"}}`, impersonated)
+		default:
+			w.Write([]byte(`{"id":"r1"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := New(server.URL, WithAutoRefresh(AutoRefreshOptions{Threshold: 5 * time.Minute}))
+	client.AuthStore.Save(testToken(time.Now().Add(time.Hour), ""), map[string]interface{}{"id": "su", "collectionName": "_superusers"})
+	other, err := client.Collection("users").Impersonate("u2", 60, "", "", nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Impersonate() error = %v", err)
+	}
+	if other.AuthStore.Token() != impersonated {
+		t.Fatalf("impersonated client token = %q", other.AuthStore.Token())
+	}
+	if _, err := other.Collection("posts").GetOneCtx(context.Background(), "r1", nil); err != nil {
+		t.Fatalf("GetOneCtx() error = %v", err)
+	}
+	if got := atomic.LoadInt32(&refreshes); got != 0 {
+		t.Errorf("sent %d auth-refresh requests for an impersonate token", got)
+	}
+}
@@ -3,6 +3,7 @@ package bosbase
 import (
     "encoding/base64"
     "encoding/json"
+    "errors"
     "fmt"
     "strings"
     "sync"
@@ -11,6 +12,20 @@ import (
 
 type AuthListener func(token string, record map[string]interface{})
 
+// AuthStorer is implemented by auth state stores. The client and services
+// only depend on this interface, so custom stores (e.g. backed by a keyring or
+// a shared cache) can be plugged in with WithAuthStore.
+type AuthStorer interface {
+    Token() string
+    Record() map[string]interface{}
+    // IsValid reports whether a non-expired token is stored.
+    IsValid() bool
+    Save(token string, record map[string]interface{})
+    Clear()
+    AddListener(fn AuthListener) string
+    RemoveListener(id string)
+}
+
 // AuthStore keeps token and auth record in memory.
 type AuthStore struct {
     mu        sync.RWMutex
@@ -18,6 +33,9 @@ type AuthStore struct {
     record    map[string]interface{}
     listeners map[string]AuthListener
     nextID    int64
+
+    expireHandlers map[string]func()
+    expireTimer    *time.Timer
 }
 
 func NewAuthStore() *AuthStore {
@@ -49,38 +67,8 @@ func (s *AuthStore) IsValid() bool {
     token := s.token
     s.mu.RUnlock()
 
-    if token == "" {
-        return false
-    }
-    parts := splitToken(token)
-    if len(parts) != 3 {
-        return false
-    }
-
-    payloadPart := parts[1]
-    padding := len(payloadPart) % 4
-    if padding > 0 {
-        payloadPart += strings.Repeat("=", 4-padding)
-    }
-    decoded, err := base64.RawURLEncoding.DecodeString(payloadPart)
-    if err != nil {
-        return false
-    }
-
-    var payload map[string]interface{}
-    if err := json.Unmarshal(decoded, &payload); err != nil {
-        return false
-    }
-
-    expVal, ok := payload["exp"]
-    if !ok {
-        return false
-    }
-    expFloat, ok := expVal.(float64)
-    if !ok {
-        return false
-    }
-    return int64(expFloat) > time.Now().Unix()
+    exp, ok := tokenExpiry(token)
+    return ok && exp.After(time.Now())
 }
 
 func (s *AuthStore) AddListener(fn AuthListener) string {
@@ -103,6 +91,7 @@ func (s *AuthStore) Save(token string, record map[string]interface{}) {
     s.mu.Lock()
     s.token = token
     s.record = record
+    s.armExpireLocked()
     listeners := make([]AuthListener, 0, len(s.listeners))
     for _, fn := range s.listeners {
         listeners = append(listeners, fn)
@@ -121,6 +110,267 @@ func (s *AuthStore) Clear() {
     s.Save("", nil)
 }
 
+// OnExpire registers fn to be called when the stored token expires. The
+// timer is re-armed on every Save, so fn only fires for a token that is still
+// stored at its expiry time. The returned function unregisters fn.
+func (s *AuthStore) OnExpire(fn func()) (cancel func()) {
+    s.mu.Lock()
+    if s.expireHandlers == nil {
+        s.expireHandlers = make(map[string]func())
+    }
+    s.nextID++
+    key := fmt.Sprintf("expire-%d", s.nextID)
+    s.expireHandlers[key] = fn
+    if len(s.expireHandlers) == 1 {
+        s.armExpireLocked()
+    }
+    s.mu.Unlock()
+
+    return func() {
+        s.mu.Lock()
+        delete(s.expireHandlers, key)
+        if len(s.expireHandlers) == 0 && s.expireTimer != nil {
+            s.expireTimer.Stop()
+            s.expireTimer = nil
+        }
+        s.mu.Unlock()
+    }
+}
+
+// armExpireLocked (re)schedules the expire handlers for the current token.
+// s.mu must be held.
+func (s *AuthStore) armExpireLocked() {
+    if s.expireTimer != nil {
+        s.expireTimer.Stop()
+        s.expireTimer = nil
+    }
+    if len(s.expireHandlers) == 0 {
+        return
+    }
+    token := s.token
+    exp, ok := tokenExpiry(token)
+    if !ok {
+        return
+    }
+    delay := time.Until(exp)
+    if delay < 0 {
+        delay = 0
+    }
+    s.expireTimer = time.AfterFunc(delay, func() {
+        s.mu.Lock()
+        if s.token != token {
+            s.mu.Unlock()
+            return
+        }
+        handlers := make([]func(), 0, len(s.expireHandlers))
+        for _, fn := range s.expireHandlers {
+            handlers = append(handlers, fn)
+        }
+        s.mu.Unlock()
+
+        for _, fn := range handlers {
+            func(cb func()) {
+                defer func() { recover() }()
+                cb()
+            }(fn)
+        }
+    })
+}
+
+// TokenClaims holds the decoded payload of an auth token.
+type TokenClaims struct {
+    // ID is the id of the record the token was issued for.
+    ID           string
+    CollectionID string
+    // Type is the token type, e.g. "auth", "file" or "verification".
+    Type        string
+    Refreshable bool
+    ExpiresAt   time.Time
+    IssuedAt    time.Time
+    // Raw contains all claims as decoded from the payload.
+    Raw map[string]interface{}
+}
+
+// ParseTokenClaims decodes the payload of a JWT. The signature is NOT
+// verified, so the claims must only be used for client-side decisions.
+func ParseTokenClaims(token string) (TokenClaims, error) {
+    parts := splitToken(token)
+    if len(parts) != 3 {
+        return TokenClaims{}, errors.New("invalid token: expected 3 segments")
+    }
+    payloadPart := parts[1]
+    padding := len(payloadPart) % 4
+    if padding > 0 {
+        payloadPart += strings.Repeat("=", 4-padding)
+    }
+    decoded, err := base64.URLEncoding.DecodeString(payloadPart)
+    if err != nil {
+        return TokenClaims{}, fmt.Errorf("invalid token payload: %w", err)
+    }
+    var raw map[string]interface{}
+    if err := json.Unmarshal(decoded, &raw); err != nil {
+        return TokenClaims{}, fmt.Errorf("invalid token payload: %w", err)
+    }
+
+    claims := TokenClaims{Raw: raw}
+    claims.ID, _ = raw["id"].(string)
+    claims.CollectionID, _ = raw["collectionId"].(string)
+    claims.Type, _ = raw["type"].(string)
+    claims.Refreshable, _ = raw["refreshable"].(bool)
+    if exp, ok := raw["exp"].(float64); ok {
+        claims.ExpiresAt = time.Unix(int64(exp), 0)
+    }
+    if iat, ok := raw["iat"].(float64); ok {
+        claims.IssuedAt = time.Unix(int64(iat), 0)
+    }
+    return claims, nil
+}
+
+// Claims returns the decoded claims of the stored token (see AuthClaims).
+func (s *AuthStore) Claims() TokenClaims {
+    return AuthClaims(s)
+}
+
+// ExpiresIn returns the remaining lifetime of the stored token (see
+// AuthExpiresIn).
+func (s *AuthStore) ExpiresIn() time.Duration {
+    return AuthExpiresIn(s)
+}
+
+// IsSuperuser reports whether the stored token is a superuser auth token
+// (see IsSuperuserAuth).
+func (s *AuthStore) IsSuperuser() bool {
+    return IsSuperuserAuth(s)
+}
+
+// IsAuthCollection reports whether the stored token is an auth token of the
+// collection with the given name or id (see IsCollectionAuth).
+func (s *AuthStore) IsAuthCollection(name string) bool {
+    return IsCollectionAuth(s, name)
+}
+
+// AuthClaims returns the decoded claims of the token stored in store (zero
+// value when the store is empty or the token is malformed). It works with any
+// AuthStorer, e.g. AuthClaims(client.AuthStore).
+func AuthClaims(store AuthStorer) TokenClaims {
+    claims, _ := ParseTokenClaims(store.Token())
+    return claims
+}
+
+// AuthExpiresIn returns the remaining lifetime of the token stored in store,
+// or 0 when there is no valid token.
+func AuthExpiresIn(store AuthStorer) time.Duration {
+    exp, ok := tokenExpiry(store.Token())
+    if !ok {
+        return 0
+    }
+    if d := time.Until(exp); d > 0 {
+        return d
+    }
+    return 0
+}
+
+// IsSuperuserAuth reports whether store holds a superuser auth token, i.e.
+// an auth token of the _superusers collection according to the stored record
+// or the token claims.
+func IsSuperuserAuth(store AuthStorer) bool {
+    claims := AuthClaims(store)
+    if claims.Type != "auth" {
+        return false
+    }
+    name, _ := store.Record()["collectionName"].(string)
+    if name == "" {
+        name, _ = claims.Raw["collectionName"].(string)
+    }
+    return name == "_superusers"
+}
+
+// IsCollectionAuth reports whether store holds an auth token of the
+// collection with the given name or id.
+func IsCollectionAuth(store AuthStorer, name string) bool {
+    claims := AuthClaims(store)
+    if claims.Type != "auth" || name == "" {
+        return false
+    }
+    if claims.CollectionID == name {
+        return true
+    }
+    collectionName, _ := store.Record()["collectionName"].(string)
+    return collectionName == name
+}
+
+// OnAuthExpire registers fn to be called when the token stored in store
+// expires, like AuthStore.OnExpire. Stores without an OnExpire method are
+// watched through an auth listener. The returned function unregisters fn.
+func OnAuthExpire(store AuthStorer, fn func()) (cancel func()) {
+    if s, ok := store.(interface{ OnExpire(func()) func() }); ok {
+        return s.OnExpire(fn)
+    }
+    w := &expireWatch{store: store, fn: fn}
+    w.listenerID = store.AddListener(func(token string, _ map[string]interface{}) {
+        w.arm(token)
+    })
+    w.arm(store.Token())
+    return w.cancel
+}
+
+// expireWatch implements OnAuthExpire for stores without OnExpire.
+type expireWatch struct {
+    store      AuthStorer
+    fn         func()
+    listenerID string
+
+    mu       sync.Mutex
+    timer    *time.Timer
+    canceled bool
+}
+
+func (w *expireWatch) arm(token string) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if w.timer != nil {
+        w.timer.Stop()
+        w.timer = nil
+    }
+    exp, ok := tokenExpiry(token)
+    if w.canceled || !ok {
+        return
+    }
+    w.timer = time.AfterFunc(time.Until(exp), func() {
+        w.mu.Lock()
+        canceled := w.canceled
+        w.mu.Unlock()
+        if canceled || w.store.Token() != token {
+            return
+        }
+        defer func() { recover() }()
+        w.fn()
+    })
+}
+
+func (w *expireWatch) cancel() {
+    w.store.RemoveListener(w.listenerID)
+    w.mu.Lock()
+    w.canceled = true
+    if w.timer != nil {
+        w.timer.Stop()
+        w.timer = nil
+    }
+    w.mu.Unlock()
+}
+
 func splitToken(token string) []string {
     return strings.Split(token, ".")
 }
+
+// tokenExpiry returns the exp claim of a JWT.
+func tokenExpiry(token string) (time.Time, bool) {
+    if token == "" {
+        return time.Time{}, false
+    }
+    claims, err := ParseTokenClaims(token)
+    if err != nil || claims.ExpiresAt.IsZero() {
+        return time.Time{}, false
+    }
+    return claims.ExpiresAt, true
+}
@@ -0,0 +1,185 @@
+package bosbase
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileAuthStore is an AuthStore persisted to a JSON file, so CLI tools keep
+// their session across restarts. Writes are atomic (temp file + rename) and
+// the file is created with 0600 permissions.
+type FileAuthStore struct {
+	*AuthStore
+
+	path string
+	aead cipher.AEAD
+
+	writeMu sync.Mutex
+	errMu   sync.RWMutex
+	err     error
+}
+
+type fileAuthState struct {
+	Token  string                 `json:"token"`
+	Record map[string]interface{} `json:"record,omitempty"`
+}
+
+// NewFileAuthStore loads the auth state stored at path (if any) and persists
+// every subsequent change to it.
+func NewFileAuthStore(path string) (*FileAuthStore, error) {
+	return newFileAuthStore(path, nil)
+}
+
+// NewEncryptedFileAuthStore is like NewFileAuthStore but encrypts the file
+// with AES-256-GCM using a key derived from secret via SHA-256. The secret
+// should be high-entropy (e.g. random bytes kept in an OS keyring).
+func NewEncryptedFileAuthStore(path string, secret []byte) (*FileAuthStore, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("auth store secret must not be empty")
+	}
+	key := sha256.Sum256(secret)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return newFileAuthStore(path, aead)
+}
+
+func newFileAuthStore(path string, aead cipher.AEAD) (*FileAuthStore, error) {
+	if path == "" {
+		return nil, errors.New("auth store path must not be empty")
+	}
+	s := &FileAuthStore{AuthStore: NewAuthStore(), path: path, aead: aead}
+	state, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	s.AuthStore.Save(state.Token, state.Record)
+	return s, nil
+}
+
+// Path returns the file the auth state is persisted to.
+func (s *FileAuthStore) Path() string {
+	return s.path
+}
+
+// Err returns the error of the last failed write, or nil once a write succeeds.
+func (s *FileAuthStore) Err() error {
+	s.errMu.RLock()
+	defer s.errMu.RUnlock()
+	return s.err
+}
+
+// Save stores the auth state in memory and writes it to the file.
+func (s *FileAuthStore) Save(token string, record map[string]interface{}) {
+	s.AuthStore.Save(token, record)
+	s.persist()
+}
+
+// Clear resets the auth state and removes the file.
+func (s *FileAuthStore) Clear() {
+	s.Save("", nil)
+}
+
+// persist writes the current in-memory state, so concurrent saves always
+// leave the file matching the latest state.
+func (s *FileAuthStore) persist() {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	var err error
+	token := s.Token()
+	if token == "" {
+		if err = os.Remove(s.path); errors.Is(err, os.ErrNotExist) {
+			err = nil
+		}
+	} else {
+		err = s.write(fileAuthState{Token: token, Record: s.Record()})
+	}
+
+	s.errMu.Lock()
+	s.err = err
+	s.errMu.Unlock()
+}
+
+func (s *FileAuthStore) write(state fileAuthState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if s.aead != nil {
+		nonce := make([]byte, s.aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return err
+		}
+		data = s.aead.Seal(nonce, nonce, data, nil)
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, s.path)
+}
+
+func (s *FileAuthStore) load() (fileAuthState, error) {
+	var state fileAuthState
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if len(data) == 0 {
+		return state, nil
+	}
+	if s.aead != nil {
+		size := s.aead.NonceSize()
+		if len(data) < size {
+			return state, fmt.Errorf("auth store %s: encrypted data too short", s.path)
+		}
+		data, err = s.aead.Open(nil, data[:size], data[size:], nil)
+		if err != nil {
+			return state, fmt.Errorf("auth store %s: cannot decrypt (wrong secret?): %w", s.path, err)
+		}
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("auth store %s: %w", s.path, err)
+	}
+	return state, nil
+}
@@ -0,0 +1,187 @@
+package bosbase
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileAuthStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "auth.json")
+	token := testToken(time.Now().Add(time.Hour), "")
+
+	store, err := NewFileAuthStore(path)
+	if err != nil {
+		t.Fatalf("NewFileAuthStore() error = %v", err)
+	}
+	if store.Token() != "" {
+		t.Fatal("a missing file should load an empty store")
+	}
+	store.Save(token, map[string]interface{}{"id": "u1", "email": "a@b.c"})
+	if err := store.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+			t.Errorf("file mode = %v (%v), want 0600", info.Mode().Perm(), err)
+		}
+		if info, err := os.Stat(filepath.Dir(path)); err != nil || info.Mode().Perm() != 0o700 {
+			t.Errorf("dir mode = %v (%v), want 0700", info.Mode().Perm(), err)
+		}
+	}
+
+	reloaded, err := NewFileAuthStore(path)
+	if err != nil {
+		t.Fatalf("NewFileAuthStore() error = %v", err)
+	}
+	if reloaded.Token() != token || reloaded.Record()["email"] != "a@b.c" {
+		t.Errorf("reloaded %q %v", reloaded.Token(), reloaded.Record())
+	}
+
+	reloaded.Clear()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Clear() should remove the file, Stat() error = %v", err)
+	}
+}
+
+func TestFileAuthStoreWritesAtomically(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "auth.json")
+	store, err := NewFileAuthStore(path)
+	if err != nil {
+		t.Fatalf("NewFileAuthStore() error = %v", err)
+	}
+	store.Save("initial", map[string]interface{}{"id": "u0"})
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			// readers never see a partially written file
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Errorf("ReadFile() error = %v", err)
+				return
+			}
+			var state fileAuthState
+			if err := json.Unmarshal(data, &state); err != nil || state.Token == "" {
+				t.Errorf("read a partial file %q: %v", data, err)
+				return
+			}
+		}
+	}()
+	var writers sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		writers.Add(1)
+		go func(i int) {
+			defer writers.Done()
+			for j := 0; j < 20; j++ {
+				store.Save(fmt.Sprintf("token-%d-%d", i, j), map[string]interface{}{"id": "u1", "padding": bytes.Repeat([]byte("x"), 4096)})
+			}
+		}(i)
+	}
+	writers.Wait()
+	close(done)
+	wg.Wait()
+
+	// the file matches the latest in-memory state and no temp files are left
+	reloaded, err := NewFileAuthStore(path)
+	if err != nil {
+		t.Fatalf("NewFileAuthStore() error = %v", err)
+	}
+	if reloaded.Token() != store.Token() {
+		t.Errorf("file token %q, want %q", reloaded.Token(), store.Token())
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d entries, want only auth.json", len(entries))
+	}
+}
+
+func TestFileAuthStoreReportsWriteErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.json")
+	store, err := NewFileAuthStore(path)
+	if err != nil {
+		t.Fatalf("NewFileAuthStore() error = %v", err)
+	}
+	// a non-empty directory in place of the file makes the rename fail
+	if err := os.MkdirAll(filepath.Join(path, "blocker"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	store.Save("token", nil)
+	if store.Err() == nil {
+		t.Fatal("Err() should report the failed write")
+	}
+	if store.Token() != "token" {
+		t.Error("a failed write must keep the in-memory state")
+	}
+
+	os.RemoveAll(path)
+	store.Save("token", nil)
+	if err := store.Err(); err != nil {
+		t.Errorf("Err() = %v after a successful write", err)
+	}
+}
+
+func TestEncryptedFileAuthStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.bin")
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	token := testToken(time.Now().Add(time.Hour), "")
+
+	if _, err := NewEncryptedFileAuthStore(path, nil); err == nil {
+		t.Error("an empty secret should be rejected")
+	}
+
+	store, err := NewEncryptedFileAuthStore(path, secret)
+	if err != nil {
+		t.Fatalf("NewEncryptedFileAuthStore() error = %v", err)
+	}
+	store.Save(token, map[string]interface{}{"id": "u1", "email": "a@b.c"})
+	if err := store.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if bytes.Contains(data, []byte(token)) || bytes.Contains(data, []byte("a@b.c")) {
+		t.Fatal("the file holds the auth state in plain text")
+	}
+
+	reloaded, err := NewEncryptedFileAuthStore(path, secret)
+	if err != nil {
+		t.Fatalf("NewEncryptedFileAuthStore() error = %v", err)
+	}
+	if reloaded.Token() != token || reloaded.Record()["email"] != "a@b.c" {
+		t.Errorf("reloaded %q %v", reloaded.Token(), reloaded.Record())
+	}
+
+	if _, err := NewEncryptedFileAuthStore(path, []byte("wrong secret")); err == nil {
+		t.Error("a wrong secret should fail to load")
+	}
+	if _, err := NewFileAuthStore(path); err == nil {
+		t.Error("loading an encrypted file as plain JSON should fail")
+	}
+
+	corrupted := append([]byte(nil), data...)
+	corrupted[len(corrupted)-1] ^= 0xff
+	for name, content := range map[string][]byte{"corrupted": corrupted, "truncated": data[:4]} {
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewEncryptedFileAuthStore(path, secret); err == nil {
+			t.Errorf("a %s file should fail to load", name)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package bosbase
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTokenClaims(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	claims, err := ParseTokenClaims(testToken(exp, `,"refreshable":true,"email":"a@b.c"`))
+	if err != nil {
+		t.Fatalf("ParseTokenClaims() error = %v", err)
+	}
+	if claims.ID != "u1" || claims.CollectionID != "c1" || claims.Type != "auth" || !claims.Refreshable || !claims.ExpiresAt.Equal(exp) {
+		t.Errorf("claims = %+v", claims)
+	}
+	if claims.Raw["email"] != "a@b.c" {
+		t.Errorf("Raw = %v", claims.Raw)
+	}
+
+	for _, token := range []string{"", "abc", "a.b", "a.!!!.c", "a." + "bm90IGpzb24" + ".c"} {
+		if _, err := ParseTokenClaims(token); err == nil {
+			t.Errorf("ParseTokenClaims(%q) should fail", token)
+		}
+	}
+}
+
+func TestAuthHelpers(t *testing.T) {
+	var store AuthStorer = NewAuthStore()
+	if AuthExpiresIn(store) != 0 || IsSuperuserAuth(store) || IsCollectionAuth(store, "users") {
+		t.Fatal("an empty store has no auth")
+	}
+
+	token := testToken(time.Now().Add(time.Hour), "")
+	store.Save(token, map[string]interface{}{"id": "u1", "collectionName": "users"})
+	if got := AuthExpiresIn(store); got <= 59*time.Minute || got > time.Hour {
+		t.Errorf("AuthExpiresIn() = %v", got)
+	}
+	if !IsCollectionAuth(store, "users") || !IsCollectionAuth(store, "c1") || IsCollectionAuth(store, "posts") {
+		t.Error("IsCollectionAuth should match the collection name or id")
+	}
+	if IsSuperuserAuth(store) {
+		t.Error("a users token is not a superuser token")
+	}
+
+	store.Save(token, map[string]interface{}{"id": "u1", "collectionName": "_superusers"})
+	if !IsSuperuserAuth(store) {
+		t.Error("IsSuperuserAuth should use the record collectionName")
+	}
+
+	store.Save(testToken(time.Now().Add(time.Hour), `,"collectionName":"_superusers"`), nil)
+	if !IsSuperuserAuth(store) {
+		t.Error("IsSuperuserAuth should fall back to the token claims")
+	}
+
+	store.Save(testToken(time.Now().Add(time.Hour), ""), nil)
+	if IsSuperuserAuth(store) {
+		t.Error("IsSuperuserAuth must not guess from the collection id")
+	}
+}
+
+// listenerStore implements AuthStorer without OnExpire.
+type listenerStore struct {
+	inner *AuthStore
+}
+
+func (s listenerStore) Token() string                           { return s.inner.Token() }
+func (s listenerStore) Record() map[string]interface{}          { return s.inner.Record() }
+func (s listenerStore) IsValid() bool                           { return s.inner.IsValid() }
+func (s listenerStore) Save(t string, r map[string]interface{}) { s.inner.Save(t, r) }
+func (s listenerStore) Clear()                                  { s.inner.Clear() }
+func (s listenerStore) AddListener(fn AuthListener) string      { return s.inner.AddListener(fn) }
+func (s listenerStore) RemoveListener(id string)                { s.inner.RemoveListener(id) }
+
+func TestOnAuthExpire(t *testing.T) {
+	for name, store := range map[string]AuthStorer{
+		"AuthStore":         NewAuthStore(),
+		"custom AuthStorer": listenerStore{inner: NewAuthStore()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			fired := make(chan struct{}, 2)
+			cancel := OnAuthExpire(store, func() { fired <- struct{}{} })
+			defer cancel()
+
+			// replaced before expiring: must not fire
+			store.Save(testToken(time.Now().Add(time.Second), ""), nil)
+			store.Save(testToken(time.Now().Add(time.Hour), ""), nil)
+			select {
+			case <-fired:
+				t.Fatal("fired for a replaced token")
+			case <-time.After(1500 * time.Millisecond):
+			}
+
+			store.Save(testToken(time.Now(), ""), nil)
+			select {
+			case <-fired:
+			case <-time.After(2 * time.Second):
+				t.Fatal("did not fire for an expired token")
+			}
+
+			cancel()
+			store.Save(testToken(time.Now(), ""), nil)
+			select {
+			case <-fired:
+				t.Fatal("fired after cancel")
+			case <-time.After(100 * time.Millisecond):
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package bosbase
 
-import "net/http"
+import (
+    "context"
+    "net/http"
+)
 
 type BackupService struct {
     BaseService
@@ -11,7 +14,11 @@ func NewBackupService(client *BosBase) *BackupService {
 }
 
 func (s *BackupService) GetFullList(query map[string]interface{}, headers map[string]string) ([]map[string]interface{}, error) {
-    data, err := s.client.Send("/api/backups", &RequestOptions{Query: query, Headers: headers})
+    return s.GetFullListCtx(context.Background(), query, headers)
+}
+
+func (s *BackupService) GetFullListCtx(ctx context.Context, query map[string]interface{}, headers map[string]string) ([]map[string]interface{}, error) {
+    data, err := s.client.SendContext(ctx, "/api/backups", &RequestOptions{Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -28,27 +35,43 @@ func (s *BackupService) GetFullList(query map[string]interface{}, headers map[st
 }
 
 func (s *BackupService) Create(name string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+    return s.CreateCtx(context.Background(), name, body, query, headers)
+}
+
+func (s *BackupService) CreateCtx(ctx context.Context, name string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
     }
     payload["name"] = name
-    _, err := s.client.Send("/api/backups", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
+    _, err := s.client.SendContext(ctx, "/api/backups", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
     return err
 }
 
 func (s *BackupService) Upload(files map[string]FileParam, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
-    _, err := s.client.Send("/api/backups/upload", &RequestOptions{Method: http.MethodPost, Body: body, Query: query, Headers: headers, Files: files})
+    return s.UploadCtx(context.Background(), files, body, query, headers)
+}
+
+func (s *BackupService) UploadCtx(ctx context.Context, files map[string]FileParam, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+    _, err := s.client.SendContext(ctx, "/api/backups/upload", &RequestOptions{Method: http.MethodPost, Body: body, Query: query, Headers: headers, Files: files})
     return err
 }
 
 func (s *BackupService) Delete(key string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
-    _, err := s.client.Send("/api/backups/"+encodePathSegment(key), &RequestOptions{Method: http.MethodDelete, Body: body, Query: query, Headers: headers})
+    return s.DeleteCtx(context.Background(), key, body, query, headers)
+}
+
+func (s *BackupService) DeleteCtx(ctx context.Context, key string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+    _, err := s.client.SendContext(ctx, "/api/backups/"+encodePathSegment(key), &RequestOptions{Method: http.MethodDelete, Body: body, Query: query, Headers: headers})
     return err
 }
 
 func (s *BackupService) Restore(key string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
-    _, err := s.client.Send("/api/backups/"+encodePathSegment(key)+"/restore", &RequestOptions{Method: http.MethodPost, Body: body, Query: query, Headers: headers})
+    return s.RestoreCtx(context.Background(), key, body, query, headers)
+}
+
+func (s *BackupService) RestoreCtx(ctx context.Context, key string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+    _, err := s.client.SendContext(ctx, "/api/backups/"+encodePathSegment(key)+"/restore", &RequestOptions{Method: http.MethodPost, Body: body, Query: query, Headers: headers})
     return err
 }
 
@@ -1,6 +1,7 @@
 package bosbase
 
 import (
+    "context"
     "fmt"
     "net/http"
 )
@@ -43,6 +44,10 @@ func (b *BatchService) queueRequest(method, url string, headers map[string]strin
 }
 
 func (b *BatchService) Send(body map[string]interface{}, query map[string]interface{}, headers map[string]string) ([]map[string]interface{}, error) {
+    return b.SendCtx(context.Background(), body, query, headers)
+}
+
+func (b *BatchService) SendCtx(ctx context.Context, body map[string]interface{}, query map[string]interface{}, headers map[string]string) ([]map[string]interface{}, error) {
     requestsPayload := make([]map[string]interface{}, 0, len(b.requests))
     attachments := map[string]FileParam{}
 
@@ -64,7 +69,7 @@ func (b *BatchService) Send(body map[string]interface{}, query map[string]interf
     }
     payload["requests"] = requestsPayload
 
-    data, err := b.client.Send("/api/batch", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers, Files: attachments})
+    data, err := b.client.SendContext(ctx, "/api/batch", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers, Files: attachments})
     b.requests = nil
     if err != nil {
         return nil, err
@@ -1,6 +1,9 @@
 package bosbase
 
-import "net/http"
+import (
+    "context"
+    "net/http"
+)
 
 type CacheService struct {
     BaseService
@@ -11,7 +14,11 @@ func NewCacheService(client *BosBase) *CacheService {
 }
 
 func (s *CacheService) List(query map[string]interface{}, headers map[string]string) ([]map[string]interface{}, error) {
-    data, err := s.client.Send("/api/cache", &RequestOptions{Query: query, Headers: headers})
+    return s.ListCtx(context.Background(), query, headers)
+}
+
+func (s *CacheService) ListCtx(ctx context.Context, query map[string]interface{}, headers map[string]string) ([]map[string]interface{}, error) {
+    data, err := s.client.SendContext(ctx, "/api/cache", &RequestOptions{Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -35,6 +42,10 @@ func (s *CacheService) List(query map[string]interface{}, headers map[string]str
 }
 
 func (s *CacheService) Create(name string, sizeBytes, defaultTTLSeconds, readTimeoutMs *int, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.CreateCtx(context.Background(), name, sizeBytes, defaultTTLSeconds, readTimeoutMs, body, query, headers)
+}
+
+func (s *CacheService) CreateCtx(ctx context.Context, name string, sizeBytes, defaultTTLSeconds, readTimeoutMs *int, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
@@ -49,7 +60,7 @@ func (s *CacheService) Create(name string, sizeBytes, defaultTTLSeconds, readTim
     if readTimeoutMs != nil {
         payload["readTimeoutMs"] = *readTimeoutMs
     }
-    data, err := s.client.Send("/api/cache", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
+    data, err := s.client.SendContext(ctx, "/api/cache", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -60,7 +71,11 @@ func (s *CacheService) Create(name string, sizeBytes, defaultTTLSeconds, readTim
 }
 
 func (s *CacheService) Update(name string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
-    data, err := s.client.Send("/api/cache/"+encodePathSegment(name), &RequestOptions{Method: http.MethodPatch, Body: body, Query: query, Headers: headers})
+    return s.UpdateCtx(context.Background(), name, body, query, headers)
+}
+
+func (s *CacheService) UpdateCtx(ctx context.Context, name string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    data, err := s.client.SendContext(ctx, "/api/cache/"+encodePathSegment(name), &RequestOptions{Method: http.MethodPatch, Body: body, Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -71,11 +86,19 @@ func (s *CacheService) Update(name string, body map[string]interface{}, query ma
 }
 
 func (s *CacheService) Delete(name string, query map[string]interface{}, headers map[string]string) error {
-    _, err := s.client.Send("/api/cache/"+encodePathSegment(name), &RequestOptions{Method: http.MethodDelete, Query: query, Headers: headers})
+    return s.DeleteCtx(context.Background(), name, query, headers)
+}
+
+func (s *CacheService) DeleteCtx(ctx context.Context, name string, query map[string]interface{}, headers map[string]string) error {
+    _, err := s.client.SendContext(ctx, "/api/cache/"+encodePathSegment(name), &RequestOptions{Method: http.MethodDelete, Query: query, Headers: headers})
     return err
 }
 
 func (s *CacheService) SetEntry(cache, key string, value interface{}, ttlSeconds *int, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.SetEntryCtx(context.Background(), cache, key, value, ttlSeconds, body, query, headers)
+}
+
+func (s *CacheService) SetEntryCtx(ctx context.Context, cache, key string, value interface{}, ttlSeconds *int, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
@@ -85,7 +108,7 @@ func (s *CacheService) SetEntry(cache, key string, value interface{}, ttlSeconds
         payload["ttlSeconds"] = *ttlSeconds
     }
     path := "/api/cache/" + encodePathSegment(cache) + "/entries/" + encodePathSegment(key)
-    data, err := s.client.Send(path, &RequestOptions{Method: http.MethodPut, Body: payload, Query: query, Headers: headers})
+    data, err := s.client.SendContext(ctx, path, &RequestOptions{Method: http.MethodPut, Body: payload, Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -96,8 +119,12 @@ func (s *CacheService) SetEntry(cache, key string, value interface{}, ttlSeconds
 }
 
 func (s *CacheService) GetEntry(cache, key string, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.GetEntryCtx(context.Background(), cache, key, query, headers)
+}
+
+func (s *CacheService) GetEntryCtx(ctx context.Context, cache, key string, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     path := "/api/cache/" + encodePathSegment(cache) + "/entries/" + encodePathSegment(key)
-    data, err := s.client.Send(path, &RequestOptions{Query: query, Headers: headers})
+    data, err := s.client.SendContext(ctx, path, &RequestOptions{Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -108,6 +135,10 @@ func (s *CacheService) GetEntry(cache, key string, query map[string]interface{},
 }
 
 func (s *CacheService) RenewEntry(cache, key string, ttlSeconds *int, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.RenewEntryCtx(context.Background(), cache, key, ttlSeconds, body, query, headers)
+}
+
+func (s *CacheService) RenewEntryCtx(ctx context.Context, cache, key string, ttlSeconds *int, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
@@ -116,7 +147,7 @@ func (s *CacheService) RenewEntry(cache, key string, ttlSeconds *int, body map[s
         payload["ttlSeconds"] = *ttlSeconds
     }
     path := "/api/cache/" + encodePathSegment(cache) + "/entries/" + encodePathSegment(key)
-    data, err := s.client.Send(path, &RequestOptions{Method: http.MethodPatch, Body: payload, Query: query, Headers: headers})
+    data, err := s.client.SendContext(ctx, path, &RequestOptions{Method: http.MethodPatch, Body: payload, Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -127,7 +158,11 @@ func (s *CacheService) RenewEntry(cache, key string, ttlSeconds *int, body map[s
 }
 
 func (s *CacheService) DeleteEntry(cache, key string, query map[string]interface{}, headers map[string]string) error {
+    return s.DeleteEntryCtx(context.Background(), cache, key, query, headers)
+}
+
+func (s *CacheService) DeleteEntryCtx(ctx context.Context, cache, key string, query map[string]interface{}, headers map[string]string) error {
     path := "/api/cache/" + encodePathSegment(cache) + "/entries/" + encodePathSegment(key)
-    _, err := s.client.Send(path, &RequestOptions{Method: http.MethodDelete, Query: query, Headers: headers})
+    _, err := s.client.SendContext(ctx, path, &RequestOptions{Method: http.MethodDelete, Query: query, Headers: headers})
     return err
 }
@@ -5,25 +5,35 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
 	"net/textproto"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/bosbase/go-sdk/filter"
 )
 
 const userAgent = "bosbase-go-sdk/0.1.0"
 
 // FileParam represents a file part in multipart requests.
+//
+// File parts are streamed to the server. Size is optional; when it is zero the
+// size is detected from readers implementing Len() or io.Seeker so the
+// request can carry an exact Content-Length.
 type FileParam struct {
 	Filename    string
 	Reader      io.Reader
 	ContentType string
+	Size        int64
+	// OnProgress reports the bytes of this file written to the request body.
+	OnProgress ProgressFunc
 }
 
+// ProgressFunc reports upload progress. Total is -1 when the size is unknown.
+type ProgressFunc func(sent, total int64)
+
 // RequestOptions describes a generic HTTP call.
 type RequestOptions struct {
 	Method  string
@@ -32,6 +42,14 @@ type RequestOptions struct {
 	Body    interface{}
 	Files   map[string]FileParam
 	Timeout time.Duration
+	// Idempotent marks the request as safe to retry regardless of its method.
+	Idempotent bool
+	// OnProgress reports the bytes of the request body sent so far.
+	OnProgress ProgressFunc
+	// Stream leaves the response body unread: Send returns the *http.Response
+	// and the caller must close its Body. Error statuses are still decoded
+	// into a ClientResponseError. The client default timeout is not applied.
+	Stream bool
 }
 
 // HookOptions passed to BeforeSend allowing mutation.
@@ -55,14 +73,17 @@ type BosBase struct {
 	BaseURL   string
 	Lang      string
 	Timeout   time.Duration
-	AuthStore *AuthStore
+	AuthStore AuthStorer
 
 	BeforeSend func(url string, options *HookOptions) (*HookOverride, error)
 	AfterSend  func(resp *http.Response, data interface{}) (interface{}, error)
 
-	httpClient *http.Client
-	mu         sync.Mutex
-	records    map[string]*RecordService
+	httpClient  *http.Client
+	retryPolicy *RetryPolicy
+	middlewares []Middleware
+	autoRefresh *autoRefresher
+	mu          sync.Mutex
+	records     map[string]*RecordService
 
 	Collections  *CollectionService
 	Files        *FileService
@@ -89,8 +110,9 @@ func WithLanguage(lang string) ClientOption {
 	return func(c *BosBase) { c.Lang = lang }
 }
 
-// WithAuthStore sets a custom auth store implementation.
-func WithAuthStore(store *AuthStore) ClientOption {
+// WithAuthStore sets a custom auth store implementation, such as a
+// FileAuthStore that persists the session across restarts.
+func WithAuthStore(store AuthStorer) ClientOption {
 	return func(c *BosBase) {
 		if store != nil {
 			c.AuthStore = store
@@ -119,7 +141,6 @@ func New(baseURL string, opts ...ClientOption) *BosBase {
 		Lang:      "en-US",
 		Timeout:   30 * time.Second,
 		AuthStore: NewAuthStore(),
-		records:   make(map[string]*RecordService),
 	}
 	for _, opt := range opts {
 		opt(c)
@@ -130,24 +151,115 @@ func New(baseURL string, opts ...ClientOption) *BosBase {
 	if c.httpClient == nil {
 		c.httpClient = &http.Client{Timeout: c.Timeout}
 	}
-	c.Collections = NewCollectionService(c)
-	c.Files = NewFileService(c)
-	c.Logs = NewLogService(c)
-	c.Realtime = NewRealtimeService(c)
-	c.Settings = NewSettingsService(c)
-	c.Health = NewHealthService(c)
-	c.Backups = NewBackupService(c)
-	c.Crons = NewCronService(c)
-	c.Vectors = NewVectorService(c)
-	c.LangChaingo = NewLangChaingoService(c)
-	c.LLMDocuments = NewLLMDocumentService(c)
-	c.Caches = NewCacheService(c)
-	c.GraphQL = NewGraphQLService(c)
-	c.SQL = NewSQLService(c)
-	c.PubSub = NewPubSubService(c)
+	c.initServices()
 	return c
 }
 
+// clientServices holds the services of a client in a single allocation,
+// keeping New and Clone cheap. The realtime and pub/sub services only
+// allocate their state once they are used.
+type clientServices struct {
+	collections  CollectionService
+	files        FileService
+	logs         LogService
+	realtime     RealtimeService
+	settings     SettingsService
+	health       HealthService
+	backups      BackupService
+	crons        CronService
+	vectors      VectorService
+	langChaingo  LangChaingoService
+	llmDocuments LLMDocumentService
+	caches       CacheService
+	graphQL      GraphQLService
+	sql          SQLService
+	pubSub       PubSubService
+}
+
+func (c *BosBase) initServices() {
+	base := BaseService{client: c}
+	s := &clientServices{
+		collections:  CollectionService{BaseCrudService: NewBaseCrudService(c, collectionsPath)},
+		files:        FileService{base},
+		logs:         LogService{base},
+		realtime:     RealtimeService{BaseService: base},
+		settings:     SettingsService{base},
+		health:       HealthService{base},
+		backups:      BackupService{base},
+		crons:        CronService{base},
+		vectors:      VectorService{base, "/api/vectors"},
+		langChaingo:  LangChaingoService{base, "/api/langchaingo"},
+		llmDocuments: LLMDocumentService{base, "/api/llm-documents"},
+		caches:       CacheService{base},
+		graphQL:      GraphQLService{base},
+		sql:          SQLService{base},
+		pubSub:       PubSubService{BaseService: base},
+	}
+	c.Collections = &s.collections
+	c.Files = &s.files
+	c.Logs = &s.logs
+	c.Realtime = &s.realtime
+	c.Settings = &s.settings
+	c.Health = &s.health
+	c.Backups = &s.backups
+	c.Crons = &s.crons
+	c.Vectors = &s.vectors
+	c.LangChaingo = &s.langChaingo
+	c.LLMDocuments = &s.llmDocuments
+	c.Caches = &s.caches
+	c.GraphQL = &s.graphQL
+	c.SQL = &s.sql
+	c.PubSub = &s.pubSub
+}
+
+// Clone returns a client that shares the HTTP client, middlewares, hooks,
+// retry policy and configuration of c, but has its own in-memory AuthStore
+// seeded with a copy of c's current auth state. Realtime and pub/sub
+// connections are not shared; a clone only sets them up when they are used.
+// Clones are cheap enough to create per request.
+func (c *BosBase) Clone() *BosBase {
+	clone := c.cloneConfig()
+	if c.autoRefresh != nil {
+		clone.autoRefresh = &autoRefresher{opts: c.autoRefresh.opts}
+	}
+	if c.AuthStore != nil {
+		clone.AuthStore.Save(c.AuthStore.Token(), c.AuthStore.Record())
+	}
+	return clone
+}
+
+// WithAuth returns a clone of c (see Clone) authenticated as another user.
+// Auto refresh keeps refreshing the new token, but a Reauthenticate provider
+// of c is not inherited so the clone never falls back to c's credentials.
+func (c *BosBase) WithAuth(token string, record map[string]interface{}) *BosBase {
+	clone := c.cloneConfig()
+	if c.autoRefresh != nil {
+		clone.autoRefresh = &autoRefresher{opts: AutoRefreshOptions{Threshold: c.autoRefresh.opts.Threshold}}
+	}
+	clone.AuthStore.Save(token, record)
+	return clone
+}
+
+func (c *BosBase) cloneConfig() *BosBase {
+	c.mu.Lock()
+	middlewares := append([]Middleware(nil), c.middlewares...)
+	c.mu.Unlock()
+
+	clone := &BosBase{
+		BaseURL:     c.BaseURL,
+		Lang:        c.Lang,
+		Timeout:     c.Timeout,
+		AuthStore:   NewAuthStore(),
+		BeforeSend:  c.BeforeSend,
+		AfterSend:   c.AfterSend,
+		httpClient:  c.httpClient,
+		retryPolicy: c.retryPolicy,
+		middlewares: middlewares,
+	}
+	clone.initServices()
+	return clone
+}
+
 // Close cleans up open realtime/pubsub connections.
 func (c *BosBase) Close() {
 	if c.Realtime != nil {
@@ -165,43 +277,22 @@ func (c *BosBase) Collection(collectionIDOrName string) *RecordService {
 	if svc, ok := c.records[collectionIDOrName]; ok {
 		return svc
 	}
+	if c.records == nil {
+		c.records = make(map[string]*RecordService)
+	}
 	svc := NewRecordService(c, collectionIDOrName)
 	c.records[collectionIDOrName] = svc
 	return svc
 }
 
-// Filter interpolates placeholders in filter expressions safely.
+// Filter replaces {:name} placeholders in expr with safely escaped literals
+// of the params values. Strings, datetimes and other values are quoted while
+// numbers, booleans and nil are emitted bare. If a value cannot be encoded the
+// returned filter matches nothing. See the filter subpackage for a builder.
 func (c *BosBase) Filter(expr string, params map[string]interface{}) string {
-	if len(params) == 0 {
-		return expr
-	}
-	result := expr
-	for key, val := range params {
-		placeholder := "{:" + key + "}"
-		switch v := val.(type) {
-		case string:
-			safe := strings.ReplaceAll(v, "'", "\\'")
-			result = strings.ReplaceAll(result, placeholder, "'"+safe+"'")
-		case nil:
-			result = strings.ReplaceAll(result, placeholder, "null")
-		case bool:
-			if v {
-				result = strings.ReplaceAll(result, placeholder, "true")
-			} else {
-				result = strings.ReplaceAll(result, placeholder, "false")
-			}
-		case time.Time:
-			ts := v.Format("2006-01-02 15:04:05")
-			result = strings.ReplaceAll(result, placeholder, "'"+ts+"'")
-		default:
-			b, err := json.Marshal(v)
-			if err != nil {
-				b, _ = json.Marshal(fmt.Sprint(v))
-			}
-			serialized := string(b)
-			serialized = strings.ReplaceAll(serialized, "'", "\\'")
-			result = strings.ReplaceAll(result, placeholder, "'"+serialized+"'")
-		}
+	result, err := filter.Format(expr, params)
+	if err != nil {
+		return "1 = 0"
 	}
 	return result
 }
@@ -239,6 +330,16 @@ func (c *BosBase) GetFileURL(record map[string]interface{}, filename string, opt
 
 // Send executes an HTTP request to the BosBase API.
 func (c *BosBase) Send(path string, options *RequestOptions) (interface{}, error) {
+	return c.SendContext(context.Background(), path, options)
+}
+
+// SendContext executes an HTTP request to the BosBase API bound to ctx.
+// Cancelling ctx aborts the in-flight request and returns a
+// ClientResponseError with IsAbort set.
+func (c *BosBase) SendContext(ctx context.Context, path string, options *RequestOptions) (interface{}, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	if options == nil {
 		options = &RequestOptions{}
 	}
@@ -247,8 +348,7 @@ func (c *BosBase) Send(path string, options *RequestOptions) (interface{}, error
 		method = http.MethodGet
 	}
 
-	currentQuery := cloneQuery(options.Query)
-	urlStr := c.BuildURL(path, currentQuery)
+	query := cloneQuery(options.Query)
 
 	headers := map[string]string{
 		"Accept-Language": c.Lang,
@@ -261,132 +361,137 @@ func (c *BosBase) Send(path string, options *RequestOptions) (interface{}, error
 		headers["Authorization"] = c.AuthStore.Token()
 	}
 
-	files := cloneFiles(options.Files)
-	payload := toSerializable(options.Body)
-	hookOpts := &HookOptions{
-		Method:  method,
-		Headers: cloneHeaders(headers),
-		Body:    payload,
-		Query:   cloneQuery(currentQuery),
-		Files:   cloneFiles(files),
-		Timeout: options.Timeout,
+	req := &Request{
+		Method:     method,
+		URL:        c.BuildURL(path, query),
+		Path:       path,
+		Headers:    headers,
+		Query:      query,
+		Body:       toSerializable(options.Body),
+		Files:      cloneFiles(options.Files),
+		Timeout:    options.Timeout,
+		Idempotent: options.Idempotent,
+		OnProgress: options.OnProgress,
+		Stream:     options.Stream,
+	}
+	resp, err := c.handler()(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, nil
 	}
+	return resp.Data, nil
+}
 
-	if c.BeforeSend != nil {
-		override, err := c.BeforeSend(urlStr, hookOpts)
-		if err != nil {
-			return nil, err
-		}
-		method = strings.ToUpper(hookOpts.Method)
-		headers = cloneHeaders(hookOpts.Headers)
-		currentQuery = cloneQuery(hookOpts.Query)
-		files = cloneFiles(hookOpts.Files)
-		payload = hookOpts.Body
-		urlStr = c.BuildURL(path, currentQuery)
-		if override != nil {
-			if override.URL != "" {
-				urlStr = override.URL
-			}
-			if override.Options != nil {
-				if override.Options.Method != "" {
-					method = strings.ToUpper(override.Options.Method)
-				}
-				if override.Options.Headers != nil {
-					headers = cloneHeaders(override.Options.Headers)
-				}
-				if override.Options.Query != nil {
-					currentQuery = cloneQuery(override.Options.Query)
-					urlStr = c.BuildURL(path, currentQuery)
-				}
-				if override.Options.Body != nil {
-					payload = override.Options.Body
-				}
-				if override.Options.Files != nil {
-					files = cloneFiles(override.Options.Files)
-				}
-				if override.Options.Timeout > 0 {
-					options.Timeout = override.Options.Timeout
-				}
-			}
-		}
+// transport is the innermost handler of the middleware chain. It performs the
+// HTTP call (including retries) and decodes the response.
+func (c *BosBase) transport(ctx context.Context, req *Request) (*Response, error) {
+	method := strings.ToUpper(strings.TrimSpace(req.Method))
+	if method == "" {
+		method = http.MethodGet
+	}
+	urlStr := req.URL
+	if urlStr == "" {
+		urlStr = c.BuildURL(req.Path, req.Query)
 	}
+	payload := toSerializable(req.Body)
+	files := req.Files
+	timeout := req.Timeout
+	if timeout <= 0 && !req.Stream {
+		timeout = c.Timeout
+	}
+	idempotent := req.Idempotent || c.retryPolicy.allowsMethod(method)
 
-	payload = toSerializable(payload)
+	resp, data, err := c.sendWithRetry(ctx, idempotent, files, func() (*http.Response, interface{}, error) {
+		return c.roundTrip(ctx, req, method, urlStr, payload, timeout)
+	})
+	return &Response{HTTP: resp, Data: data}, err
+}
 
+// roundTrip performs a single HTTP attempt and decodes the response body.
+// On error statuses the (already closed) response is returned together with
+// the ClientResponseError so callers can inspect headers like Retry-After.
+func (c *BosBase) roundTrip(ctx context.Context, r *Request, method, urlStr string, payload interface{}, timeout time.Duration) (*http.Response, interface{}, error) {
+	files := r.Files
+	progress := r.OnProgress
 	var bodyReader io.Reader
 	reqHeaders := make(http.Header)
-	for k, v := range headers {
+	for k, v := range r.Headers {
 		reqHeaders.Set(k, v)
 	}
 
+	contentLength := int64(-1)
 	if len(files) > 0 {
-		buf := &bytes.Buffer{}
-		writer := multipart.NewWriter(buf)
 		jsonPayload := payload
 		if jsonPayload == nil {
 			jsonPayload = map[string]interface{}{}
 		}
 		raw, _ := json.Marshal(jsonPayload)
-		_ = writer.WriteField("@jsonPayload", string(raw))
-		for field, file := range files {
-			partHeaders := textprotoMIMEHeader(field, file)
-			part, err := writer.CreatePart(partHeaders)
-			if err != nil {
-				return nil, err
-			}
-			if file.Reader != nil {
-				if _, err := io.Copy(part, file.Reader); err != nil {
-					return nil, err
-				}
-			}
-		}
-		writer.Close()
-		bodyReader = buf
-		reqHeaders.Set("Content-Type", writer.FormDataContentType())
+		body, contentType, length, wait := streamMultipart(raw, files)
+		// closing the pipe stops the writer goroutine if the body wasn't fully
+		// consumed; wait for it so a retry can safely rewind the files
+		defer func() {
+			body.Close()
+			wait()
+		}()
+		bodyReader = body
+		contentLength = length
+		reqHeaders.Set("Content-Type", contentType)
 	} else if payload != nil {
 		raw, err := json.Marshal(payload)
 		if err != nil {
-			return nil, err
+			return nil, nil, &ClientResponseError{URL: urlStr, OriginalErr: err}
 		}
 		bodyReader = bytes.NewReader(raw)
+		contentLength = int64(len(raw))
 		reqHeaders.Set("Content-Type", "application/json")
 	}
+	if progress != nil && bodyReader != nil {
+		bodyReader = &progressReader{r: bodyReader, total: contentLength, fn: progress}
+	}
 
 	req, err := http.NewRequest(method, urlStr, bodyReader)
 	if err != nil {
-		return nil, &ClientResponseError{URL: urlStr, OriginalErr: err}
+		return nil, nil, &ClientResponseError{URL: urlStr, OriginalErr: err}
 	}
 	req.Header = reqHeaders
-	timeout := options.Timeout
-	if timeout <= 0 {
-		timeout = c.Timeout
+	if contentLength >= 0 {
+		req.ContentLength = contentLength
 	}
 	client := c.httpClient
 	if client == nil {
 		client = &http.Client{Timeout: timeout}
 	}
-	if timeout > 0 && client.Timeout != timeout {
+	if (timeout > 0 || r.Stream) && client.Timeout != timeout {
 		clone := *client
 		clone.Timeout = timeout
 		client = &clone
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-	req = req.WithContext(ctx)
+	reqCtx, cancel := ctx, context.CancelFunc(func() {})
+	if timeout > 0 {
+		reqCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	req = req.WithContext(reqCtx)
 
 	resp, err := client.Do(req)
 	if err != nil {
-		isAbort := errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
-		return nil, &ClientResponseError{URL: urlStr, OriginalErr: err, IsAbort: isAbort}
+		cancel()
+		return nil, nil, &ClientResponseError{URL: urlStr, OriginalErr: err, IsAbort: isAbortErr(ctx, err)}
+	}
+	if r.Stream && resp.StatusCode < 400 {
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		return resp, resp, nil
 	}
+	defer cancel()
 	defer resp.Body.Close()
 
 	var data interface{}
 	if resp.StatusCode != http.StatusNoContent {
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return nil, &ClientResponseError{URL: urlStr, OriginalErr: err}
+			return nil, nil, &ClientResponseError{URL: urlStr, OriginalErr: err, IsAbort: isAbortErr(ctx, err)}
 		}
 		contentType := resp.Header.Get("Content-Type")
 		if strings.Contains(strings.ToLower(contentType), "application/json") {
@@ -407,18 +512,30 @@ func (c *BosBase) Send(path string, options *RequestOptions) (interface{}, error
 
 	if resp.StatusCode >= 400 {
 		respMap, _ := data.(map[string]interface{})
-		return nil, &ClientResponseError{URL: urlStr, Status: resp.StatusCode, Response: respMap}
+		return resp, nil, &ClientResponseError{URL: urlStr, Status: resp.StatusCode, Response: respMap}
 	}
 
-	if c.AfterSend != nil {
-		var err error
-		data, err = c.AfterSend(resp, data)
-		if err != nil {
-			return nil, err
-		}
-	}
+	return resp, data, nil
+}
+
+// cancelOnClose releases the request context once a streamed body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
 
-	return data, nil
+// isAbortErr reports whether err was caused by ctx being cancelled or timing out.
+func isAbortErr(ctx context.Context, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	return ctx.Err() != nil
 }
 
 func cloneHeaders(src map[string]string) map[string]string {
@@ -478,7 +595,12 @@ func (c *BosBase) ResolveRelative(path string) string {
 
 // GetFileToken fetches a temporary file token.
 func (c *BosBase) GetFileToken(body map[string]interface{}, query map[string]interface{}) (string, error) {
-	data, err := c.Send("/api/files/token", &RequestOptions{Method: http.MethodPost, Body: body, Query: query})
+	return c.GetFileTokenCtx(context.Background(), body, query)
+}
+
+// GetFileTokenCtx is like GetFileToken but honors ctx.
+func (c *BosBase) GetFileTokenCtx(ctx context.Context, body map[string]interface{}, query map[string]interface{}) (string, error) {
+	data, err := c.SendContext(ctx, "/api/files/token", &RequestOptions{Method: http.MethodPost, Body: body, Query: query})
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,31 @@
+package bosbase
+
+import "testing"
+
+func TestWithAuthIsolatesAuthState(t *testing.T) {
+	client := New("http://127.0.0.1:8090")
+	client.AuthStore.Save("parent", map[string]interface{}{"id": "p"})
+
+	clone := client.WithAuth("child", map[string]interface{}{"id": "c"})
+	if client.AuthStore.Token() != "parent" || clone.AuthStore.Token() != "child" {
+		t.Fatalf("tokens = %q, %q", client.AuthStore.Token(), clone.AuthStore.Token())
+	}
+	if clone.Realtime == client.Realtime || clone.PubSub == client.PubSub {
+		t.Error("realtime and pub/sub services must not be shared")
+	}
+	if clone.Collection("posts").client != clone || clone.Files.client != clone || clone.Realtime.client != clone {
+		t.Error("clone services must send through the clone")
+	}
+	clone.Close()
+}
+
+func TestWithAuthIsCheap(t *testing.T) {
+	client := New("http://127.0.0.1:8090")
+	allocs := testing.AllocsPerRun(100, func() {
+		client.WithAuth("token", nil)
+	})
+	// client, services, auth store and its listener map
+	if allocs > 6 {
+		t.Errorf("WithAuth allocates %v times per call", allocs)
+	}
+}
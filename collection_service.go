@@ -1,6 +1,7 @@
 package bosbase
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -12,38 +13,54 @@ type CollectionService struct {
 }
 
 func NewCollectionService(client *BosBase) *CollectionService {
-	svc := &CollectionService{}
-	svc.BaseCrudService = NewBaseCrudService(client, func() string { return "/api/collections" })
-	return svc
+	return &CollectionService{BaseCrudService: NewBaseCrudService(client, collectionsPath)}
 }
 
+func collectionsPath() string { return "/api/collections" }
+
 func (s *CollectionService) DeleteCollection(idOrName string, opts *CrudDeleteOptions) error {
-	return s.Delete(idOrName, opts)
+	return s.DeleteCollectionCtx(context.Background(), idOrName, opts)
+}
+
+func (s *CollectionService) DeleteCollectionCtx(ctx context.Context, idOrName string, opts *CrudDeleteOptions) error {
+	return s.DeleteCtx(ctx, idOrName, opts)
 }
 
 func (s *CollectionService) Truncate(idOrName string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+	return s.TruncateCtx(context.Background(), idOrName, body, query, headers)
+}
+
+func (s *CollectionService) TruncateCtx(ctx context.Context, idOrName string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
 	path := fmt.Sprintf("%s/%s/truncate", s.basePath(), encodePathSegment(idOrName))
-	_, err := s.client.Send(path, &RequestOptions{Method: "DELETE", Body: body, Query: query, Headers: headers})
+	_, err := s.client.SendContext(ctx, path, &RequestOptions{Method: "DELETE", Body: body, Query: query, Headers: headers})
 	return err
 }
 
 func (s *CollectionService) ImportCollections(collections interface{}, deleteMissing bool, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+	return s.ImportCollectionsCtx(context.Background(), collections, deleteMissing, body, query, headers)
+}
+
+func (s *CollectionService) ImportCollectionsCtx(ctx context.Context, collections interface{}, deleteMissing bool, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
 	payload := cloneQuery(body)
 	if payload == nil {
 		payload = map[string]interface{}{}
 	}
 	payload["collections"] = collections
 	payload["deleteMissing"] = deleteMissing
-	_, err := s.client.Send(s.basePath()+"/import", &RequestOptions{Method: "PUT", Body: payload, Query: query, Headers: headers})
+	_, err := s.client.SendContext(ctx, s.basePath()+"/import", &RequestOptions{Method: "PUT", Body: payload, Query: query, Headers: headers})
 	return err
 }
 
 func (s *CollectionService) RegisterSqlTables(tables []string, query map[string]interface{}, headers map[string]string) ([]map[string]interface{}, error) {
+	return s.RegisterSqlTablesCtx(context.Background(), tables, query, headers)
+}
+
+func (s *CollectionService) RegisterSqlTablesCtx(ctx context.Context, tables []string, query map[string]interface{}, headers map[string]string) ([]map[string]interface{}, error) {
 	if len(tables) == 0 {
 		return nil, errors.New("at least one table must be specified")
 	}
 	payload := map[string]interface{}{"tables": tables}
-	data, err := s.client.Send(s.basePath()+"/sql/tables", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
+	data, err := s.client.SendContext(ctx, s.basePath()+"/sql/tables", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
 	if err != nil {
 		return nil, err
 	}
@@ -59,6 +76,10 @@ func (s *CollectionService) RegisterSqlTables(tables []string, query map[string]
 }
 
 func (s *CollectionService) ImportSqlTables(tables []SqlTableDefinition, query map[string]interface{}, headers map[string]string) (SqlTableImportResult, error) {
+	return s.ImportSqlTablesCtx(context.Background(), tables, query, headers)
+}
+
+func (s *CollectionService) ImportSqlTablesCtx(ctx context.Context, tables []SqlTableDefinition, query map[string]interface{}, headers map[string]string) (SqlTableImportResult, error) {
 	if len(tables) == 0 {
 		return SqlTableImportResult{}, errors.New("at least one table definition must be provided")
 	}
@@ -67,7 +88,7 @@ func (s *CollectionService) ImportSqlTables(tables []SqlTableDefinition, query m
 		payloadTables = append(payloadTables, tbl.ToMap())
 	}
 	payload := map[string]interface{}{"tables": payloadTables}
-	data, err := s.client.Send(s.basePath()+"/sql/import", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
+	data, err := s.client.SendContext(ctx, s.basePath()+"/sql/import", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
 	if err != nil {
 		return SqlTableImportResult{}, err
 	}
@@ -78,7 +99,11 @@ func (s *CollectionService) ImportSqlTables(tables []SqlTableDefinition, query m
 }
 
 func (s *CollectionService) GetScaffolds(body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
-	data, err := s.client.Send(s.basePath()+"/meta/scaffolds", &RequestOptions{Body: body, Query: query, Headers: headers})
+	return s.GetScaffoldsCtx(context.Background(), body, query, headers)
+}
+
+func (s *CollectionService) GetScaffoldsCtx(ctx context.Context, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+	data, err := s.client.SendContext(ctx, s.basePath()+"/meta/scaffolds", &RequestOptions{Body: body, Query: query, Headers: headers})
 	if err != nil {
 		return nil, err
 	}
@@ -88,8 +113,8 @@ func (s *CollectionService) GetScaffolds(body map[string]interface{}, query map[
 	return map[string]interface{}{}, nil
 }
 
-func (s *CollectionService) createFromScaffold(scaffoldType, name string, overrides map[string]interface{}, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
-	scaffolds, err := s.GetScaffolds(nil, query, headers)
+func (s *CollectionService) createFromScaffold(ctx context.Context, scaffoldType, name string, overrides map[string]interface{}, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+	scaffolds, err := s.GetScaffoldsCtx(ctx, nil, query, headers)
 	if err != nil {
 		return nil, err
 	}
@@ -105,30 +130,46 @@ func (s *CollectionService) createFromScaffold(scaffoldType, name string, overri
 	for k, v := range body {
 		data[k] = v
 	}
-	return s.Create(&CrudMutateOptions{Body: data, Query: query, Headers: headers})
+	return s.CreateCtx(ctx, &CrudMutateOptions{Body: data, Query: query, Headers: headers})
 }
 
 func (s *CollectionService) CreateBase(name string, overrides map[string]interface{}, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
-	return s.createFromScaffold("base", name, overrides, body, query, headers)
+	return s.CreateBaseCtx(context.Background(), name, overrides, body, query, headers)
+}
+
+func (s *CollectionService) CreateBaseCtx(ctx context.Context, name string, overrides map[string]interface{}, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+	return s.createFromScaffold(ctx, "base", name, overrides, body, query, headers)
 }
 
 func (s *CollectionService) CreateAuth(name string, overrides map[string]interface{}, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
-	return s.createFromScaffold("auth", name, overrides, body, query, headers)
+	return s.CreateAuthCtx(context.Background(), name, overrides, body, query, headers)
+}
+
+func (s *CollectionService) CreateAuthCtx(ctx context.Context, name string, overrides map[string]interface{}, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+	return s.createFromScaffold(ctx, "auth", name, overrides, body, query, headers)
 }
 
 func (s *CollectionService) CreateView(name string, viewQuery string, overrides map[string]interface{}, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+	return s.CreateViewCtx(context.Background(), name, viewQuery, overrides, body, query, headers)
+}
+
+func (s *CollectionService) CreateViewCtx(ctx context.Context, name string, viewQuery string, overrides map[string]interface{}, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
 	scaffoldOverrides := cloneQuery(overrides)
 	if viewQuery != "" {
 		scaffoldOverrides["viewQuery"] = viewQuery
 	}
-	return s.createFromScaffold("view", name, scaffoldOverrides, body, query, headers)
+	return s.createFromScaffold(ctx, "view", name, scaffoldOverrides, body, query, headers)
 }
 
 func (s *CollectionService) AddIndex(collection string, columns []string, unique bool, indexName string, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+	return s.AddIndexCtx(context.Background(), collection, columns, unique, indexName, query, headers)
+}
+
+func (s *CollectionService) AddIndexCtx(ctx context.Context, collection string, columns []string, unique bool, indexName string, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
 	if len(columns) == 0 {
 		return nil, errors.New("at least one column must be specified")
 	}
-	current, err := s.GetOne(collection, &CrudViewOptions{Query: query, Headers: headers})
+	current, err := s.GetOneCtx(ctx, collection, &CrudViewOptions{Query: query, Headers: headers})
 	if err != nil {
 		return nil, err
 	}
@@ -173,14 +214,18 @@ func (s *CollectionService) AddIndex(collection string, columns []string, unique
 	indexes = append(indexes, indexesRaw...)
 	indexes = append(indexes, indexSQL)
 	current["indexes"] = indexes
-	return s.Update(collection, &CrudMutateOptions{Body: current, Query: query, Headers: headers})
+	return s.UpdateCtx(ctx, collection, &CrudMutateOptions{Body: current, Query: query, Headers: headers})
 }
 
 func (s *CollectionService) RemoveIndex(collection string, columns []string, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+	return s.RemoveIndexCtx(context.Background(), collection, columns, query, headers)
+}
+
+func (s *CollectionService) RemoveIndexCtx(ctx context.Context, collection string, columns []string, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
 	if len(columns) == 0 {
 		return nil, errors.New("at least one column must be specified")
 	}
-	current, err := s.GetOne(collection, &CrudViewOptions{Query: query, Headers: headers})
+	current, err := s.GetOneCtx(ctx, collection, &CrudViewOptions{Query: query, Headers: headers})
 	if err != nil {
 		return nil, err
 	}
@@ -209,11 +254,15 @@ func (s *CollectionService) RemoveIndex(collection string, columns []string, que
 		return nil, errors.New("index not found")
 	}
 	current["indexes"] = filtered
-	return s.Update(collection, &CrudMutateOptions{Body: current, Query: query, Headers: headers})
+	return s.UpdateCtx(ctx, collection, &CrudMutateOptions{Body: current, Query: query, Headers: headers})
 }
 
 func (s *CollectionService) GetIndexes(collection string, query map[string]interface{}, headers map[string]string) ([]string, error) {
-	current, err := s.GetOne(collection, &CrudViewOptions{Query: query, Headers: headers})
+	return s.GetIndexesCtx(context.Background(), collection, query, headers)
+}
+
+func (s *CollectionService) GetIndexesCtx(ctx context.Context, collection string, query map[string]interface{}, headers map[string]string) ([]string, error) {
+	current, err := s.GetOneCtx(ctx, collection, &CrudViewOptions{Query: query, Headers: headers})
 	if err != nil {
 		return nil, err
 	}
@@ -228,8 +277,12 @@ func (s *CollectionService) GetIndexes(collection string, query map[string]inter
 }
 
 func (s *CollectionService) GetSchema(collection string, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+	return s.GetSchemaCtx(context.Background(), collection, query, headers)
+}
+
+func (s *CollectionService) GetSchemaCtx(ctx context.Context, collection string, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
 	path := fmt.Sprintf("%s/%s/schema", s.basePath(), encodePathSegment(collection))
-	data, err := s.client.Send(path, &RequestOptions{Query: query, Headers: headers})
+	data, err := s.client.SendContext(ctx, path, &RequestOptions{Query: query, Headers: headers})
 	if err != nil {
 		return nil, err
 	}
@@ -240,7 +293,11 @@ func (s *CollectionService) GetSchema(collection string, query map[string]interf
 }
 
 func (s *CollectionService) GetAllSchemas(query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
-	data, err := s.client.Send(s.basePath()+"/schemas", &RequestOptions{Query: query, Headers: headers})
+	return s.GetAllSchemasCtx(context.Background(), query, headers)
+}
+
+func (s *CollectionService) GetAllSchemasCtx(ctx context.Context, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+	data, err := s.client.SendContext(ctx, s.basePath()+"/schemas", &RequestOptions{Query: query, Headers: headers})
 	if err != nil {
 		return nil, err
 	}
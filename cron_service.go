@@ -1,6 +1,9 @@
 package bosbase
 
-import "net/http"
+import (
+    "context"
+    "net/http"
+)
 
 type CronService struct {
     BaseService
@@ -11,7 +14,11 @@ func NewCronService(client *BosBase) *CronService {
 }
 
 func (s *CronService) GetFullList(query map[string]interface{}, headers map[string]string) ([]map[string]interface{}, error) {
-    data, err := s.client.Send("/api/crons", &RequestOptions{Query: query, Headers: headers})
+    return s.GetFullListCtx(context.Background(), query, headers)
+}
+
+func (s *CronService) GetFullListCtx(ctx context.Context, query map[string]interface{}, headers map[string]string) ([]map[string]interface{}, error) {
+    data, err := s.client.SendContext(ctx, "/api/crons", &RequestOptions{Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -27,7 +34,11 @@ func (s *CronService) GetFullList(query map[string]interface{}, headers map[stri
 }
 
 func (s *CronService) Run(jobID string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+    return s.RunCtx(context.Background(), jobID, body, query, headers)
+}
+
+func (s *CronService) RunCtx(ctx context.Context, jobID string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
     path := "/api/crons/" + encodePathSegment(jobID)
-    _, err := s.client.Send(path, &RequestOptions{Method: http.MethodPost, Body: body, Query: query, Headers: headers})
+    _, err := s.client.SendContext(ctx, path, &RequestOptions{Method: http.MethodPost, Body: body, Query: query, Headers: headers})
     return err
 }
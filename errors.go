@@ -1,6 +1,40 @@
 package bosbase
 
-import "fmt"
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "sort"
+)
+
+// Sentinel errors matched by ClientResponseError through errors.Is.
+var (
+    ErrBadRequest   = errors.New("bosbase: bad request")
+    ErrValidation   = errors.New("bosbase: validation failed")
+    ErrUnauthorized = errors.New("bosbase: unauthorized")
+    ErrForbidden    = errors.New("bosbase: forbidden")
+    ErrNotFound     = errors.New("bosbase: not found")
+    ErrRateLimited  = errors.New("bosbase: rate limited")
+    ErrMFARequired  = errors.New("bosbase: mfa required")
+    ErrAborted      = errors.New("bosbase: request aborted")
+)
+
+// ErrChecksumMismatch is returned when downloaded content fails checksum verification.
+var ErrChecksumMismatch = errors.New("bosbase: checksum mismatch")
+
+// ErrSubscriptionOverflow is reported when a SubscribeChan buffer with the
+// OverflowError policy is full.
+var ErrSubscriptionOverflow = errors.New("bosbase: realtime subscription buffer overflow")
+
+// PubSub errors.
+var (
+    // ErrPubSubQueueFull is returned by Publish when the outbound queue of a
+    // disconnected PubSubService is full.
+    ErrPubSubQueueFull = errors.New("bosbase: pubsub outbound queue is full")
+    // ErrPubSubClosed is returned for requests pending when the PubSubService
+    // is disconnected.
+    ErrPubSubClosed = errors.New("bosbase: pubsub connection closed")
+)
 
 // ClientResponseError represents a normalized HTTP error from BosBase.
 type ClientResponseError struct {
@@ -9,6 +43,15 @@ type ClientResponseError struct {
     Response     map[string]interface{}
     IsAbort      bool
     OriginalErr  error
+    // Attempts is the number of attempts made when a RetryPolicy with
+    // RecordAttempts is configured.
+    Attempts     int
+}
+
+// FieldError describes a single field validation failure.
+type FieldError struct {
+    Code    string
+    Message string
 }
 
 func (e *ClientResponseError) Error() string {
@@ -19,3 +62,137 @@ func (e *ClientResponseError) Error() string {
 func (e *ClientResponseError) Unwrap() error {
     return e.OriginalErr
 }
+
+// Is matches the package sentinel errors against the error status and payload.
+func (e *ClientResponseError) Is(target error) bool {
+    switch target {
+    case ErrAborted:
+        return e.IsAbort
+    case ErrBadRequest:
+        return e.Status == http.StatusBadRequest
+    case ErrValidation:
+        return e.Status == http.StatusBadRequest && len(e.FieldErrors()) > 0
+    case ErrUnauthorized:
+        return e.Status == http.StatusUnauthorized
+    case ErrForbidden:
+        return e.Status == http.StatusForbidden
+    case ErrNotFound:
+        return e.Status == http.StatusNotFound
+    case ErrRateLimited:
+        return e.Status == http.StatusTooManyRequests
+    case ErrMFARequired:
+        return e.MFAID() != ""
+    }
+    return false
+}
+
+// Message returns the server provided error message, if any.
+func (e *ClientResponseError) Message() string {
+    if msg, ok := e.Response["message"].(string); ok {
+        return msg
+    }
+    return ""
+}
+
+// Data returns the "data" object of the error response.
+func (e *ClientResponseError) Data() map[string]interface{} {
+    data, _ := e.Response["data"].(map[string]interface{})
+    return data
+}
+
+// FieldErrors returns the field validation errors keyed by field name.
+// Nested errors (e.g. collection fields) use dotted keys like "fields.0.name".
+func (e *ClientResponseError) FieldErrors() map[string]FieldError {
+    result := map[string]FieldError{}
+    collectFieldErrors("", e.Data(), result)
+    return result
+}
+
+// FieldNames returns the sorted names of the fields that failed validation.
+func (e *ClientResponseError) FieldNames() []string {
+    fields := e.FieldErrors()
+    names := make([]string, 0, len(fields))
+    for name := range fields {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// MFAID returns the mfaId of a response that requires a second auth method.
+func (e *ClientResponseError) MFAID() string {
+    if id, ok := e.Response["mfaId"].(string); ok && id != "" {
+        return id
+    }
+    if id, ok := e.Data()["mfaId"].(string); ok {
+        return id
+    }
+    return ""
+}
+
+func collectFieldErrors(prefix string, data map[string]interface{}, out map[string]FieldError) {
+    for key, raw := range data {
+        value, ok := raw.(map[string]interface{})
+        if !ok {
+            continue
+        }
+        name := key
+        if prefix != "" {
+            name = prefix + "." + key
+        }
+        code, hasCode := value["code"].(string)
+        message, hasMessage := value["message"].(string)
+        if hasCode || hasMessage {
+            out[name] = FieldError{Code: code, Message: message}
+            continue
+        }
+        collectFieldErrors(name, value, out)
+    }
+}
+
+// IsNotFound reports whether err is a 404 response.
+func IsNotFound(err error) bool { return errors.Is(err, ErrNotFound) }
+
+// IsForbidden reports whether err is a 403 response.
+func IsForbidden(err error) bool { return errors.Is(err, ErrForbidden) }
+
+// IsUnauthorized reports whether err is a 401 response.
+func IsUnauthorized(err error) bool { return errors.Is(err, ErrUnauthorized) }
+
+// IsValidation reports whether err is a 400 response carrying field errors.
+func IsValidation(err error) bool { return errors.Is(err, ErrValidation) }
+
+// IsRateLimited reports whether err is a 429 response.
+func IsRateLimited(err error) bool { return errors.Is(err, ErrRateLimited) }
+
+// IsAbort reports whether err was caused by a cancelled or timed out request.
+func IsAbort(err error) bool { return errors.Is(err, ErrAborted) }
+
+// IsMFARequired reports whether err asks for a second auth method and returns its mfaId.
+func IsMFARequired(err error) (string, bool) {
+    var cre *ClientResponseError
+    if errors.As(err, &cre) {
+        if id := cre.MFAID(); id != "" {
+            return id, true
+        }
+    }
+    return "", false
+}
+
+// newClientError builds a ClientResponseError for failures detected locally.
+func newClientError(url string, status int, message string) *ClientResponseError {
+    return &ClientResponseError{
+        URL:    url,
+        Status: status,
+        Response: map[string]interface{}{
+            "code":    status,
+            "message": message,
+            "data":    map[string]interface{}{},
+        },
+    }
+}
+
+// newAbortError builds a ClientResponseError for a cancelled request.
+func newAbortError(url string, err error) *ClientResponseError {
+    return &ClientResponseError{URL: url, OriginalErr: err, IsAbort: true}
+}
@@ -1,6 +1,19 @@
 package bosbase
 
-import "net/http"
+import (
+    "bytes"
+    "context"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "hash"
+    "io"
+    "mime"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+)
 
 // FileURLOptions configures file URL generation.
 type FileURLOptions struct {
@@ -10,6 +23,41 @@ type FileURLOptions struct {
     Query    map[string]interface{}
 }
 
+// FileDownloadOptions configures FileService.Download and DownloadTo.
+type FileDownloadOptions struct {
+    Thumb string
+    Token string
+    // Protected requests a file token through GetToken before downloading.
+    // Without it a token is still fetched automatically when an authenticated
+    // request is rejected with 403/404.
+    Protected bool
+    // Offset and Length select a byte range; Length <= 0 reads until the end.
+    Offset int64
+    Length int64
+    // Checksum and ExpectedChecksum (hex encoded) verify the downloaded bytes
+    // once the content has been read to the end.
+    Checksum         hash.Hash
+    ExpectedChecksum string
+    // MaxResumes caps how many times DownloadTo resumes an interrupted
+    // transfer (defaults to 3).
+    MaxResumes int
+    Query      map[string]interface{}
+    Headers    map[string]string
+}
+
+// FileInfo describes downloaded file content.
+type FileInfo struct {
+    Filename     string
+    ContentType  string
+    // Size is the length of the returned content or -1 when unknown.
+    Size int64
+    // TotalSize is the full size of the file or -1 when unknown.
+    TotalSize    int64
+    Offset       int64
+    ETag         string
+    LastModified time.Time
+}
+
 type FileService struct {
     BaseService
 }
@@ -20,19 +68,10 @@ func NewFileService(client *BosBase) *FileService {
 
 // GetURL builds the download URL for a specific file.
 func (s *FileService) GetURL(record map[string]interface{}, filename string, opts *FileURLOptions) string {
-    recordID, _ := record["id"].(string)
-    if recordID == "" || filename == "" {
+    path := filePath(record, filename)
+    if path == "" {
         return ""
     }
-    collection := ""
-    if v, ok := record["collectionId"].(string); ok {
-        collection = v
-    }
-    if collection == "" {
-        if v, ok := record["collectionName"].(string); ok {
-            collection = v
-        }
-    }
     params := map[string]interface{}{}
     if opts != nil {
         for k, v := range opts.Query {
@@ -48,15 +87,296 @@ func (s *FileService) GetURL(record map[string]interface{}, filename string, opt
             params["download"] = ""
         }
     }
-    return s.client.BuildURL(
-        "/api/files/"+encodePathSegment(collection)+"/"+encodePathSegment(recordID)+"/"+encodePathSegment(filename),
-        params,
-    )
+    return s.client.BuildURL(path, params)
+}
+
+// Download opens a streaming reader for a record file. The caller must close
+// the returned reader. Thumbnails, byte ranges and protected files (through an
+// automatically acquired file token) are supported via opts.
+func (s *FileService) Download(ctx context.Context, record map[string]interface{}, filename string, opts *FileDownloadOptions) (io.ReadCloser, FileInfo, error) {
+    options := opts
+    if options == nil {
+        options = &FileDownloadOptions{}
+    }
+    path := filePath(record, filename)
+    if path == "" {
+        return nil, FileInfo{}, newClientError("", http.StatusNotFound, "Missing record id or filename.")
+    }
+    token, err := s.initialToken(ctx, options)
+    if err != nil {
+        return nil, FileInfo{}, err
+    }
+    body, info, err := s.openWithToken(ctx, path, filename, &token, options.Offset, options.Length, "", options)
+    if err != nil {
+        return nil, FileInfo{}, err
+    }
+    if options.Checksum != nil && options.ExpectedChecksum != "" {
+        options.Checksum.Reset()
+        body = &checksumReader{ReadCloser: body, hash: options.Checksum, expected: options.ExpectedChecksum}
+    }
+    return body, info, nil
+}
+
+// DownloadTo writes a record file into dst starting at opts.Offset. Transfers
+// interrupted by network errors are resumed with Range requests guarded by
+// If-Range (the ETag, or Last-Modified without one), so a file changed on the
+// server is never spliced together. When the server sends neither validator
+// an interrupted transfer fails instead of resuming.
+func (s *FileService) DownloadTo(ctx context.Context, record map[string]interface{}, filename string, dst io.WriterAt, opts *FileDownloadOptions) (FileInfo, error) {
+    options := opts
+    if options == nil {
+        options = &FileDownloadOptions{}
+    }
+    path := filePath(record, filename)
+    if path == "" {
+        return FileInfo{}, newClientError("", http.StatusNotFound, "Missing record id or filename.")
+    }
+    maxResumes := options.MaxResumes
+    if maxResumes <= 0 {
+        maxResumes = 3
+    }
+    token, err := s.initialToken(ctx, options)
+    if err != nil {
+        return FileInfo{}, err
+    }
+    if options.Checksum != nil {
+        options.Checksum.Reset()
+    }
+
+    offset := options.Offset
+    end := int64(-1)
+    if options.Length > 0 {
+        end = offset + options.Length
+    }
+    var first FileInfo
+    for attempt := 0; ; attempt++ {
+        length := int64(0)
+        if end >= 0 {
+            length = end - offset
+        }
+        body, info, err := s.openWithToken(ctx, path, filename, &token, offset, length, resumeValidator(first), options)
+        if err != nil {
+            return first, err
+        }
+        if attempt == 0 {
+            first = info
+        } else if !sameVersion(first, info) {
+            body.Close()
+            return first, newClientError(s.client.BuildURL(path, nil), http.StatusPreconditionFailed, "The file changed while resuming the download.")
+        }
+
+        var src io.Reader = body
+        if options.Checksum != nil {
+            src = io.TeeReader(body, options.Checksum)
+        }
+        n, copyErr := io.Copy(io.NewOffsetWriter(dst, offset), src)
+        body.Close()
+        offset += n
+        if copyErr == nil {
+            break
+        }
+        if ctx.Err() != nil || attempt >= maxResumes || resumeValidator(first) == "" {
+            return first, &ClientResponseError{URL: s.client.BuildURL(path, nil), OriginalErr: copyErr, IsAbort: isAbortErr(ctx, copyErr)}
+        }
+    }
+
+    if options.Checksum != nil && options.ExpectedChecksum != "" {
+        if err := verifyChecksum(options.Checksum, options.ExpectedChecksum); err != nil {
+            return first, err
+        }
+    }
+    return first, nil
+}
+
+// resumeValidator returns the If-Range value guarding a resume of the file
+// described by info, or "" when the server sent nothing to tell whether the
+// file changed in between.
+func resumeValidator(info FileInfo) string {
+    if info.ETag != "" {
+        return info.ETag
+    }
+    if !info.LastModified.IsZero() {
+        return info.LastModified.UTC().Format(http.TimeFormat)
+    }
+    return ""
+}
+
+// sameVersion reports whether a resumed response serves the same file
+// version as the first one, by the validator sent as If-Range.
+func sameVersion(first, info FileInfo) bool {
+    if first.ETag != "" {
+        return info.ETag == first.ETag
+    }
+    return info.LastModified.Equal(first.LastModified)
+}
+
+func (s *FileService) initialToken(ctx context.Context, options *FileDownloadOptions) (string, error) {
+    if options.Token != "" || !options.Protected {
+        return options.Token, nil
+    }
+    return s.GetTokenCtx(ctx, nil, nil, nil)
+}
+
+// openWithToken opens the file and retries once with a freshly acquired file
+// token when an authenticated request without token is rejected.
+func (s *FileService) openWithToken(ctx context.Context, path, filename string, token *string, offset, length int64, ifRange string, options *FileDownloadOptions) (io.ReadCloser, FileInfo, error) {
+    body, info, err := s.open(ctx, path, filename, *token, offset, length, ifRange, options)
+    if err == nil || *token != "" || !(IsForbidden(err) || IsNotFound(err)) {
+        return body, info, err
+    }
+    if s.client.AuthStore == nil || !s.client.AuthStore.IsValid() {
+        return nil, FileInfo{}, err
+    }
+    fresh, tokErr := s.GetTokenCtx(ctx, nil, nil, nil)
+    if tokErr != nil || fresh == "" {
+        return nil, FileInfo{}, err
+    }
+    *token = fresh
+    return s.open(ctx, path, filename, fresh, offset, length, ifRange, options)
+}
+
+func (s *FileService) open(ctx context.Context, path, filename, token string, offset, length int64, ifRange string, options *FileDownloadOptions) (io.ReadCloser, FileInfo, error) {
+    params := cloneQuery(options.Query)
+    if options.Thumb != "" {
+        params["thumb"] = options.Thumb
+    }
+    if token != "" {
+        params["token"] = token
+    }
+    headers := cloneHeaders(options.Headers)
+    if offset > 0 || length > 0 {
+        rangeHeader := fmt.Sprintf("bytes=%d-", offset)
+        if length > 0 {
+            rangeHeader += strconv.FormatInt(offset+length-1, 10)
+        }
+        headers["Range"] = rangeHeader
+        if ifRange != "" {
+            headers["If-Range"] = ifRange
+        }
+    }
+    data, err := s.client.SendContext(ctx, path, &RequestOptions{Query: params, Headers: headers, Stream: true})
+    if err != nil {
+        return nil, FileInfo{}, err
+    }
+
+    resp, ok := data.(*http.Response)
+    if !ok {
+        // a middleware answered the request without hitting the server
+        raw, _ := data.([]byte)
+        return io.NopCloser(bytes.NewReader(raw)), FileInfo{Filename: filename, Size: int64(len(raw)), TotalSize: int64(len(raw))}, nil
+    }
+    info := fileInfoFromResponse(resp, filename)
+    body := resp.Body
+    if resp.StatusCode != http.StatusPartialContent && offset > 0 {
+        // the server ignored the Range header; skip to the requested offset
+        if _, err := io.CopyN(io.Discard, body, offset); err != nil {
+            body.Close()
+            return nil, FileInfo{}, &ClientResponseError{URL: resp.Request.URL.String(), OriginalErr: err, IsAbort: isAbortErr(ctx, err)}
+        }
+        info.Offset = offset
+        if info.Size >= 0 {
+            info.Size -= offset
+        }
+    }
+    if resp.StatusCode != http.StatusPartialContent && length > 0 {
+        body = &limitedReadCloser{Reader: io.LimitReader(body, length), Closer: body}
+        if info.Size < 0 || info.Size > length {
+            info.Size = length
+        }
+    }
+    return body, info, nil
+}
+
+// filePath returns the API path of a record file or an empty string.
+func filePath(record map[string]interface{}, filename string) string {
+    recordID, _ := record["id"].(string)
+    if recordID == "" || filename == "" {
+        return ""
+    }
+    collection := ""
+    if v, ok := record["collectionId"].(string); ok {
+        collection = v
+    }
+    if collection == "" {
+        if v, ok := record["collectionName"].(string); ok {
+            collection = v
+        }
+    }
+    return "/api/files/" + encodePathSegment(collection) + "/" + encodePathSegment(recordID) + "/" + encodePathSegment(filename)
+}
+
+func fileInfoFromResponse(resp *http.Response, filename string) FileInfo {
+    info := FileInfo{
+        Filename:    filename,
+        ContentType: resp.Header.Get("Content-Type"),
+        Size:        resp.ContentLength,
+        TotalSize:   resp.ContentLength,
+        ETag:        resp.Header.Get("ETag"),
+    }
+    if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+        info.Filename = params["filename"]
+    }
+    if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+        info.LastModified = modified
+    }
+    if resp.StatusCode == http.StatusPartialContent {
+        info.TotalSize = -1
+        // Content-Range: bytes 100-199/1000
+        if spec := strings.TrimPrefix(resp.Header.Get("Content-Range"), "bytes "); spec != "" {
+            if slash := strings.Index(spec, "/"); slash >= 0 {
+                if total, err := strconv.ParseInt(spec[slash+1:], 10, 64); err == nil {
+                    info.TotalSize = total
+                }
+                if dash := strings.Index(spec[:slash], "-"); dash >= 0 {
+                    if start, err := strconv.ParseInt(spec[:dash], 10, 64); err == nil {
+                        info.Offset = start
+                    }
+                }
+            }
+        }
+    }
+    return info
+}
+
+type limitedReadCloser struct {
+    io.Reader
+    io.Closer
+}
+
+// checksumReader hashes the content while it is read and verifies it at EOF.
+type checksumReader struct {
+    io.ReadCloser
+    hash     hash.Hash
+    expected string
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+    n, err := r.ReadCloser.Read(p)
+    r.hash.Write(p[:n])
+    if errors.Is(err, io.EOF) {
+        if verr := verifyChecksum(r.hash, r.expected); verr != nil {
+            return n, verr
+        }
+    }
+    return n, err
+}
+
+func verifyChecksum(h hash.Hash, expected string) error {
+    actual := hex.EncodeToString(h.Sum(nil))
+    if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+        return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, expected, actual)
+    }
+    return nil
 }
 
 // GetToken requests a temporary file token.
 func (s *FileService) GetToken(body map[string]interface{}, query map[string]interface{}, headers map[string]string) (string, error) {
-    data, err := s.client.Send("/api/files/token", &RequestOptions{Method: http.MethodPost, Body: body, Query: query, Headers: headers})
+    return s.GetTokenCtx(context.Background(), body, query, headers)
+}
+
+// GetTokenCtx is like GetToken but honors ctx for cancellation and deadlines.
+func (s *FileService) GetTokenCtx(ctx context.Context, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (string, error) {
+    data, err := s.client.SendContext(ctx, "/api/files/token", &RequestOptions{Method: http.MethodPost, Body: body, Query: query, Headers: headers})
     if err != nil {
         return "", err
     }
@@ -0,0 +1,268 @@
+package bosbase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testFileRecord = map[string]interface{}{"id": "r1", "collectionId": "c1"}
+
+// bufferAt is an in-memory io.WriterAt.
+type bufferAt struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (b *bufferAt) WriteAt(p []byte, off int64) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if end := int(off) + len(p); end > len(b.buf) {
+		b.buf = append(b.buf, make([]byte, end-len(b.buf))...)
+	}
+	copy(b.buf[off:], p)
+	return len(p), nil
+}
+
+// fileServer serves content with http.ServeContent. Requests without a Range
+// header are cut after half of the content when truncate is set.
+type fileServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	content  []byte
+	etag     string
+	modified time.Time
+	truncate bool
+	ifRanges []string
+}
+
+func newFileServer(t *testing.T, content string) *fileServer {
+	s := &fileServer{content: []byte(content)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		content, etag, modified, truncate := s.content, s.etag, s.modified, s.truncate
+		if r.Header.Get("Range") != "" {
+			s.ifRanges = append(s.ifRanges, r.Header.Get("If-Range"))
+		}
+		s.mu.Unlock()
+
+		if etag != "" {
+			w.Header().Set("ETag", etag)
+		}
+		if truncate && r.Header.Get("Range") == "" {
+			if !modified.IsZero() {
+				w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+			}
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.Write(content[:len(content)/2])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "", modified, bytes.NewReader(content))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fileServer) resumes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.ifRanges...)
+}
+
+func (s *fileServer) update(fn func(s *fileServer)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s)
+}
+
+func TestFileDownloadRange(t *testing.T) {
+	server := newFileServer(t, "0123456789")
+	client := New(server.URL)
+
+	body, info, err := client.Files.Download(context.Background(), testFileRecord, "a.txt", &FileDownloadOptions{Offset: 2, Length: 5})
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	defer body.Close()
+	data, _ := io.ReadAll(body)
+	if string(data) != "23456" {
+		t.Errorf("content = %q, want %q", data, "23456")
+	}
+	if info.Offset != 2 || info.Size != 5 || info.TotalSize != 10 {
+		t.Errorf("info = %+v", info)
+	}
+}
+
+func TestFileDownloadToResume(t *testing.T) {
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	cases := []struct {
+		name        string
+		etag        string
+		modified    time.Time
+		wantIfRange string
+	}{
+		{"etag", `"v1"`, time.Time{}, `"v1"`},
+		{"last modified", "", modified, modified.Format(http.TimeFormat)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newFileServer(t, "0123456789")
+			server.update(func(s *fileServer) {
+				s.etag, s.modified, s.truncate = tc.etag, tc.modified, true
+			})
+			client := New(server.URL)
+
+			dst := &bufferAt{}
+			if _, err := client.Files.DownloadTo(context.Background(), testFileRecord, "a.txt", dst, nil); err != nil {
+				t.Fatalf("DownloadTo() error = %v", err)
+			}
+			if string(dst.buf) != "0123456789" {
+				t.Errorf("content = %q", dst.buf)
+			}
+			if got := server.resumes(); len(got) != 1 || got[0] != tc.wantIfRange {
+				t.Errorf("If-Range = %q, want %q", got, tc.wantIfRange)
+			}
+		})
+	}
+}
+
+func TestFileDownloadToChangedFile(t *testing.T) {
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	cases := []struct {
+		name   string
+		before func(s *fileServer)
+		after  func(s *fileServer)
+	}{
+		{
+			"etag",
+			func(s *fileServer) { s.etag = `"v1"` },
+			func(s *fileServer) { s.etag = `"v2"` },
+		},
+		{
+			"last modified",
+			func(s *fileServer) { s.modified = modified },
+			func(s *fileServer) { s.modified = modified.Add(time.Hour) },
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newFileServer(t, "0123456789")
+			server.update(func(s *fileServer) {
+				tc.before(s)
+				s.truncate = true
+			})
+			client := New(server.URL, WithMiddleware(func(next Handler) Handler {
+				return func(ctx context.Context, req *Request) (*Response, error) {
+					if req.Headers["Range"] != "" {
+						// the file changes before the resume
+						server.update(func(s *fileServer) {
+							tc.after(s)
+							s.content = []byte("abcdefghij")
+						})
+					}
+					return next(ctx, req)
+				}
+			}))
+
+			_, err := client.Files.DownloadTo(context.Background(), testFileRecord, "a.txt", &bufferAt{}, nil)
+			var cre *ClientResponseError
+			if !errors.As(err, &cre) || cre.Status != http.StatusPreconditionFailed {
+				t.Fatalf("DownloadTo() error = %v, want a 412 error", err)
+			}
+		})
+	}
+}
+
+func TestFileDownloadToRefusesUnguardedResume(t *testing.T) {
+	server := newFileServer(t, "0123456789")
+	server.update(func(s *fileServer) { s.truncate = true })
+	client := New(server.URL)
+
+	if _, err := client.Files.DownloadTo(context.Background(), testFileRecord, "a.txt", &bufferAt{}, nil); err == nil {
+		t.Fatal("DownloadTo() should fail without an ETag or Last-Modified to resume with")
+	}
+	if got := server.resumes(); len(got) != 0 {
+		t.Errorf("sent %d resume requests without a validator", len(got))
+	}
+}
+
+func TestFileDownloadChecksum(t *testing.T) {
+	server := newFileServer(t, "0123456789")
+	client := New(server.URL)
+	sum := sha256.Sum256([]byte("0123456789"))
+	valid := hex.EncodeToString(sum[:])
+
+	for _, tc := range []struct {
+		expected string
+		wantErr  bool
+	}{
+		{valid, false},
+		{strings.ToUpper(valid), false},
+		{strings.Repeat("0", 64), true},
+	} {
+		opts := &FileDownloadOptions{Checksum: sha256.New(), ExpectedChecksum: tc.expected}
+		_, err := client.Files.DownloadTo(context.Background(), testFileRecord, "a.txt", &bufferAt{}, opts)
+		if got := errors.Is(err, ErrChecksumMismatch); got != tc.wantErr {
+			t.Errorf("DownloadTo() with checksum %s error = %v", tc.expected, err)
+		}
+
+		body, _, err := client.Files.Download(context.Background(), testFileRecord, "a.txt", opts)
+		if err != nil {
+			t.Fatalf("Download() error = %v", err)
+		}
+		_, err = io.ReadAll(body)
+		body.Close()
+		if got := errors.Is(err, ErrChecksumMismatch); got != tc.wantErr {
+			t.Errorf("reading with checksum %s error = %v", tc.expected, err)
+		}
+	}
+}
+
+func TestFileDownloadAcquiresFileToken(t *testing.T) {
+	var tokenRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/files/token":
+			atomic.AddInt32(&tokenRequests, 1)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"token":"file-token"}`))
+		case r.URL.Query().Get("token") == "file-token":
+			w.Write([]byte("secret"))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"status":403,"message":"Forbidden."}`))
+		}
+	}))
+	defer server.Close()
+
+	client := New(server.URL)
+	body, _, err := client.Files.Download(context.Background(), testFileRecord, "a.txt", nil)
+	if err == nil {
+		body.Close()
+		t.Fatal("Download() without auth should not acquire a file token")
+	}
+
+	client.AuthStore.Save(testToken(time.Now().Add(time.Hour), ""), map[string]interface{}{"id": "u1"})
+	body, _, err = client.Files.Download(context.Background(), testFileRecord, "a.txt", nil)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	defer body.Close()
+	if data, _ := io.ReadAll(body); string(data) != "secret" || atomic.LoadInt32(&tokenRequests) != 1 {
+		t.Errorf("content = %q after %d token requests", data, atomic.LoadInt32(&tokenRequests))
+	}
+}
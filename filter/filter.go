@@ -0,0 +1,389 @@
+// Package filter builds BosBase filter expressions with correctly escaped
+// literals.
+//
+//	expr := filter.Eq("status", "active").
+//		And(filter.Gte("created", filter.TodayStart)).
+//		Or(filter.AnyEq("tags", userInput))
+//
+//	list, err := client.Collection("posts").GetList(&bosbase.CrudListOptions{
+//		Filter: expr.String(),
+//	})
+//
+// The left operand of a condition is a field name (a plain string or an
+// Identifier); the right operand is a value and is always rendered as a
+// literal unless it is an Identifier, a macro or a function call such as
+// GeoDistance.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Operator is a BosBase comparison operator.
+type Operator string
+
+const (
+	OpEq         Operator = "="
+	OpNeq        Operator = "!="
+	OpGt         Operator = ">"
+	OpGte        Operator = ">="
+	OpLt         Operator = "<"
+	OpLte        Operator = "<="
+	OpLike       Operator = "~"
+	OpNotLike    Operator = "!~"
+	OpAnyEq      Operator = "?="
+	OpAnyNeq     Operator = "?!="
+	OpAnyGt      Operator = "?>"
+	OpAnyGte     Operator = "?>="
+	OpAnyLt      Operator = "?<"
+	OpAnyLte     Operator = "?<="
+	OpAnyLike    Operator = "?~"
+	OpAnyNotLike Operator = "?!~"
+)
+
+var operators = map[Operator]bool{
+	OpEq: true, OpNeq: true, OpGt: true, OpGte: true, OpLt: true, OpLte: true,
+	OpLike: true, OpNotLike: true,
+	OpAnyEq: true, OpAnyNeq: true, OpAnyGt: true, OpAnyGte: true, OpAnyLt: true, OpAnyLte: true,
+	OpAnyLike: true, OpAnyNotLike: true,
+}
+
+const (
+	logicAnd = "&&"
+	logicOr  = "||"
+)
+
+// Expr is an immutable filter expression. The zero Expr is empty and is
+// ignored when combined with And/Or.
+type Expr struct {
+	logic string
+	items []Expr
+
+	left  operand
+	op    Operator
+	right operand
+
+	raw string
+	err error
+}
+
+// Compare builds `left op right`.
+func Compare(left interface{}, op Operator, right interface{}) Expr {
+	if !operators[op] {
+		return Expr{err: fmt.Errorf("filter: unknown operator %q", op)}
+	}
+	l, err := toOperand(left, true)
+	if err != nil {
+		return Expr{err: err}
+	}
+	r, err := toOperand(right, false)
+	if err != nil {
+		return Expr{err: err}
+	}
+	return Expr{left: l, op: op, right: r}
+}
+
+// Eq builds `field = value`.
+func Eq(field, value interface{}) Expr { return Compare(field, OpEq, value) }
+
+// Neq builds `field != value`.
+func Neq(field, value interface{}) Expr { return Compare(field, OpNeq, value) }
+
+// Gt builds `field > value`.
+func Gt(field, value interface{}) Expr { return Compare(field, OpGt, value) }
+
+// Gte builds `field >= value`.
+func Gte(field, value interface{}) Expr { return Compare(field, OpGte, value) }
+
+// Lt builds `field < value`.
+func Lt(field, value interface{}) Expr { return Compare(field, OpLt, value) }
+
+// Lte builds `field <= value`.
+func Lte(field, value interface{}) Expr { return Compare(field, OpLte, value) }
+
+// Like builds `field ~ value`. The server wraps value in % wildcards unless
+// it already contains one.
+func Like(field, value interface{}) Expr { return Compare(field, OpLike, value) }
+
+// NotLike builds `field !~ value`.
+func NotLike(field, value interface{}) Expr { return Compare(field, OpNotLike, value) }
+
+// AnyEq builds `field ?= value`, matching when any item of a multi-value field equals value.
+func AnyEq(field, value interface{}) Expr { return Compare(field, OpAnyEq, value) }
+
+// AnyNeq builds `field ?!= value`.
+func AnyNeq(field, value interface{}) Expr { return Compare(field, OpAnyNeq, value) }
+
+// AnyGt builds `field ?> value`.
+func AnyGt(field, value interface{}) Expr { return Compare(field, OpAnyGt, value) }
+
+// AnyGte builds `field ?>= value`.
+func AnyGte(field, value interface{}) Expr { return Compare(field, OpAnyGte, value) }
+
+// AnyLt builds `field ?< value`.
+func AnyLt(field, value interface{}) Expr { return Compare(field, OpAnyLt, value) }
+
+// AnyLte builds `field ?<= value`.
+func AnyLte(field, value interface{}) Expr { return Compare(field, OpAnyLte, value) }
+
+// AnyLike builds `field ?~ value`.
+func AnyLike(field, value interface{}) Expr { return Compare(field, OpAnyLike, value) }
+
+// AnyNotLike builds `field ?!~ value`.
+func AnyNotLike(field, value interface{}) Expr { return Compare(field, OpAnyNotLike, value) }
+
+// Raw wraps a hand-written expression, replacing {:name} placeholders with
+// escaped params values (see Format).
+func Raw(expr string, params map[string]interface{}) Expr {
+	formatted, err := Format(expr, params)
+	if err != nil {
+		return Expr{err: err}
+	}
+	if strings.TrimSpace(formatted) == "" {
+		return Expr{}
+	}
+	return Expr{raw: formatted}
+}
+
+// And joins the expressions with &&.
+func And(exprs ...Expr) Expr { return join(logicAnd, exprs) }
+
+// Or joins the expressions with ||.
+func Or(exprs ...Expr) Expr { return join(logicOr, exprs) }
+
+// And returns `e && others...`.
+func (e Expr) And(others ...Expr) Expr {
+	return join(logicAnd, append([]Expr{e}, others...))
+}
+
+// Or returns `e || others...`.
+func (e Expr) Or(others ...Expr) Expr {
+	return join(logicOr, append([]Expr{e}, others...))
+}
+
+func join(logic string, exprs []Expr) Expr {
+	items := make([]Expr, 0, len(exprs))
+	for _, expr := range exprs {
+		switch {
+		case expr.IsEmpty():
+			continue
+		case expr.logic == logic:
+			items = append(items, expr.items...)
+		default:
+			items = append(items, expr)
+		}
+	}
+	switch len(items) {
+	case 0:
+		return Expr{}
+	case 1:
+		return items[0]
+	}
+	return Expr{logic: logic, items: items}
+}
+
+// IsEmpty reports whether e holds no condition.
+func (e Expr) IsEmpty() bool {
+	return e.logic == "" && e.op == "" && e.raw == "" && e.err == nil
+}
+
+// Err returns the first error recorded while building e, such as an invalid
+// field name or a value that cannot be encoded.
+func (e Expr) Err() error {
+	if e.err != nil {
+		return e.err
+	}
+	for _, item := range e.items {
+		if err := item.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Build renders e, returning Err when the expression is invalid.
+func (e Expr) Build() (string, error) {
+	if err := e.Err(); err != nil {
+		return "", err
+	}
+	return e.String(), nil
+}
+
+// String renders e. Invalid parts (see Err) are rendered as an always-false
+// condition so a broken value can never widen the filter.
+func (e Expr) String() string {
+	switch {
+	case e.err != nil:
+		return "1 = 0"
+	case e.logic != "":
+		parts := make([]string, 0, len(e.items))
+		for _, item := range e.items {
+			s := item.String()
+			if item.logic != "" || item.raw != "" {
+				s = "(" + s + ")"
+			}
+			parts = append(parts, s)
+		}
+		return strings.Join(parts, " "+e.logic+" ")
+	case e.raw != "":
+		return e.raw
+	case e.op != "":
+		return e.left.String() + " " + string(e.op) + " " + e.right.String()
+	}
+	return ""
+}
+
+// Identifier is a field path, @request/@collection reference or macro that
+// is rendered verbatim, e.g. "author.name", "@request.auth.id" or "@now".
+type Identifier string
+
+// Field returns a field path identifier, useful as the right operand of a
+// field to field comparison.
+func Field(path string) Identifier { return Identifier(path) }
+
+// Request returns an `@request.<path>` identifier, e.g. Request("body.title").
+func Request(path string) Identifier { return Identifier("@request." + path) }
+
+// Auth returns an `@request.auth.<field>` identifier.
+func Auth(field string) Identifier { return Identifier("@request.auth." + field) }
+
+// Body returns an `@request.body.<field>` identifier.
+func Body(field string) Identifier { return Identifier("@request.body." + field) }
+
+// Query returns an `@request.query.<param>` identifier.
+func Query(param string) Identifier { return Identifier("@request.query." + param) }
+
+// Header returns an `@request.headers.<name>` identifier, normalizing name
+// the way the server does (lowercase, "-" replaced with "_").
+func Header(name string) Identifier {
+	return Identifier("@request.headers." + strings.ReplaceAll(strings.ToLower(name), "-", "_"))
+}
+
+// Collection returns an `@collection.<name>.<field>` identifier.
+func Collection(name, field string) Identifier {
+	return Identifier("@collection." + name + "." + field)
+}
+
+// CollectionAs returns an aliased `@collection.<name>:<alias>.<field>`
+// identifier, needed when joining the same collection more than once.
+func CollectionAs(name, alias, field string) Identifier {
+	return Identifier("@collection." + name + ":" + alias + "." + field)
+}
+
+// IsSet appends the :isset modifier (only valid for @request.* fields).
+func (i Identifier) IsSet() Identifier { return i + ":isset" }
+
+// Length appends the :length modifier.
+func (i Identifier) Length() Identifier { return i + ":length" }
+
+// Each appends the :each modifier.
+func (i Identifier) Each() Identifier { return i + ":each" }
+
+// Lower appends the :lower modifier.
+func (i Identifier) Lower() Identifier { return i + ":lower" }
+
+// Datetime macros, evaluated by the server in UTC.
+const (
+	Now        Identifier = "@now"
+	Second     Identifier = "@second"
+	Minute     Identifier = "@minute"
+	Hour       Identifier = "@hour"
+	Weekday    Identifier = "@weekday"
+	Day        Identifier = "@day"
+	Month      Identifier = "@month"
+	Year       Identifier = "@year"
+	Yesterday  Identifier = "@yesterday"
+	Tomorrow   Identifier = "@tomorrow"
+	TodayStart Identifier = "@todayStart"
+	TodayEnd   Identifier = "@todayEnd"
+	MonthStart Identifier = "@monthStart"
+	MonthEnd   Identifier = "@monthEnd"
+	YearStart  Identifier = "@yearStart"
+	YearEnd    Identifier = "@yearEnd"
+)
+
+// Func is a function call operand such as geoDistance(...).
+type Func struct {
+	name string
+	args []operand
+	err  error
+}
+
+// GeoDistance builds `geoDistance(lonA, latA, lonB, latB)`, the Haversine
+// distance in kilometers. String arguments are field paths; numbers are
+// rendered as literals.
+//
+//	filter.Lt(filter.GeoDistance("location.lon", "location.lat", 23.32, 42.69), 25)
+func GeoDistance(lonA, latA, lonB, latB interface{}) Func {
+	fn := Func{name: "geoDistance"}
+	for _, arg := range []interface{}{lonA, latA, lonB, latB} {
+		op, err := toOperand(arg, true)
+		if err != nil {
+			fn.err = err
+			return fn
+		}
+		fn.args = append(fn.args, op)
+	}
+	return fn
+}
+
+var identifierPattern = regexp.MustCompile(`^@?[A-Za-z0-9_]+([.:][A-Za-z0-9_]+)*$`)
+
+type operandKind int
+
+const (
+	literalOperand operandKind = iota
+	identifierOperand
+	funcOperand
+)
+
+type operand struct {
+	kind  operandKind
+	name  string
+	args  []operand
+	value interface{}
+	text  string
+}
+
+func (o operand) String() string {
+	if o.kind == funcOperand {
+		args := make([]string, len(o.args))
+		for i, arg := range o.args {
+			args[i] = arg.String()
+		}
+		return o.name + "(" + strings.Join(args, ", ") + ")"
+	}
+	return o.text
+}
+
+// toOperand converts a builder argument; plain strings are identifiers on
+// the left side and literals on the right.
+func toOperand(v interface{}, stringIsIdentifier bool) (operand, error) {
+	switch val := v.(type) {
+	case Identifier:
+		return identifier(string(val))
+	case Func:
+		if val.err != nil {
+			return operand{}, val.err
+		}
+		return operand{kind: funcOperand, name: val.name, args: val.args}, nil
+	case string:
+		if stringIsIdentifier {
+			return identifier(val)
+		}
+	}
+	text, err := Literal(v)
+	if err != nil {
+		return operand{}, err
+	}
+	return operand{kind: literalOperand, value: v, text: text}, nil
+}
+
+func identifier(name string) (operand, error) {
+	if !identifierPattern.MatchString(name) {
+		return operand{}, fmt.Errorf("filter: invalid identifier %q", name)
+	}
+	return operand{kind: identifierOperand, name: name, text: name}, nil
+}
@@ -0,0 +1,262 @@
+package filter
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestQuote(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{``, `''`},
+		{`plain`, `'plain'`},
+		{`O'Reilly`, `"O'Reilly"`},
+		{`say "hi"`, `'say "hi"'`},
+		{`it's "quoted"`, `'it\'s "quoted"'`},
+		{`a\b`, `'a\b'`},
+		{`C:\path\to`, `'C:\path\to'`},
+		{`\'`, `"\'"`},
+		{`"\'`, `'"\\''`},
+		{`{:name}`, `'{:name}'`},
+		{`&& || ( )`, `'&& || ( )'`},
+		{`% _ wildcards`, `'% _ wildcards'`},
+		{"line\nbreak", "'line\nbreak'"},
+		{`żółw 🐢 日本`, `'żółw 🐢 日本'`},
+	}
+	for _, tc := range cases {
+		got, err := Quote(tc.in)
+		if err != nil {
+			t.Errorf("Quote(%q) error = %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Quote(%q) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestQuoteTrailingBackslash(t *testing.T) {
+	for _, in := range []string{`\`, `a\`, `a\\`, `it's\`} {
+		if got, err := Quote(in); err == nil {
+			t.Errorf("Quote(%q) = %s, want an error", in, got)
+		}
+	}
+}
+
+type stringer struct{}
+
+func (stringer) String() string { return "it's me" }
+
+func TestLiteral(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.FixedZone("CET", 3600))
+	var nilTime *time.Time
+
+	cases := []struct {
+		name string
+		in   interface{}
+		want string
+	}{
+		{"nil", nil, `null`},
+		{"true", true, `true`},
+		{"false", false, `false`},
+		{"int", -42, `-42`},
+		{"int8", int8(8), `8`},
+		{"uint64", uint64(math.MaxUint64), `18446744073709551615`},
+		{"float", 1.5, `1.5`},
+		{"float32", float32(0.25), `0.25`},
+		{"large float", 1e21, `1000000000000000000000`},
+		{"json number", json.Number("12.50"), `12.50`},
+		{"string", `x'y"z`, `'x\'y"z'`},
+		{"time", ts, `'2024-01-02 02:04:05.006Z'`},
+		{"time pointer", &ts, `'2024-01-02 02:04:05.006Z'`},
+		{"nil time pointer", nilTime, `null`},
+		{"stringer", stringer{}, `"it's me"`},
+		{"string slice", []string{"a", "b"}, `'["a","b"]'`},
+		{"int slice", []int{1, 2}, `'[1,2]'`},
+		{"map", map[string]int{"a": 1}, `'{"a":1}'`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Literal(tc.in)
+			if err != nil {
+				t.Fatalf("Literal(%v) error = %v", tc.in, err)
+			}
+			if got != tc.want {
+				t.Errorf("Literal(%v) = %s, want %s", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLiteralErrors(t *testing.T) {
+	for _, in := range []interface{}{
+		math.NaN(),
+		math.Inf(1),
+		json.Number("1e"),
+		make(chan int),
+		`ends with \`,
+	} {
+		if got, err := Literal(in); err == nil {
+			t.Errorf("Literal(%v) = %s, want an error", in, got)
+		}
+	}
+}
+
+func TestOperators(t *testing.T) {
+	cases := []struct {
+		expr Expr
+		want string
+	}{
+		{Eq("status", "active"), `status = 'active'`},
+		{Neq("status", "draft"), `status != 'draft'`},
+		{Gt("count", 5), `count > 5`},
+		{Gte("count", 5), `count >= 5`},
+		{Lt("count", 5), `count < 5`},
+		{Lte("count", 5), `count <= 5`},
+		{Like("title", "go%"), `title ~ 'go%'`},
+		{NotLike("title", "spam"), `title !~ 'spam'`},
+		{AnyEq("tags", "go"), `tags ?= 'go'`},
+		{AnyNeq("tags", "go"), `tags ?!= 'go'`},
+		{AnyGt("scores", 1), `scores ?> 1`},
+		{AnyGte("scores", 1), `scores ?>= 1`},
+		{AnyLt("scores", 1), `scores ?< 1`},
+		{AnyLte("scores", 1), `scores ?<= 1`},
+		{AnyLike("tags", "g"), `tags ?~ 'g'`},
+		{AnyNotLike("tags", "g"), `tags ?!~ 'g'`},
+		{Compare("a", OpEq, nil), `a = null`},
+	}
+	for _, tc := range cases {
+		got, err := tc.expr.Build()
+		if err != nil {
+			t.Errorf("Build() error = %v, want %s", err, tc.want)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Build() = %s, want %s", got, tc.want)
+		}
+	}
+
+	if err := Compare("a", Operator("=="), 1).Err(); err == nil {
+		t.Error("Compare with an unknown operator should fail")
+	}
+}
+
+func TestIdentifiersAndModifiers(t *testing.T) {
+	cases := []struct {
+		expr Expr
+		want string
+	}{
+		{Eq("author", Auth("id")), `author = @request.auth.id`},
+		{Eq(Body("title").IsSet(), true), `@request.body.title:isset = true`},
+		{Gt(Field("tags").Length(), 2), `tags:length > 2`},
+		{Like(Body("tags").Each(), "x"), `@request.body.tags:each ~ 'x'`},
+		{Eq(Field("title").Lower(), "go"), `title:lower = 'go'`},
+		{Eq(Query("page"), "1"), `@request.query.page = '1'`},
+		{Eq(Header("X-Token"), "abc"), `@request.headers.x_token = 'abc'`},
+		{Eq(Request("method"), "GET"), `@request.method = 'GET'`},
+		{Eq("author", Collection("users", "id")), `author = @collection.users.id`},
+		{Eq("author", CollectionAs("users", "u2", "id")), `author = @collection.users:u2.id`},
+		{Eq("author.name", Field("editor.name")), `author.name = editor.name`},
+		{Gte("created", TodayStart), `created >= @todayStart`},
+		{Lt("created", Now), `created < @now`},
+		{Eq(Weekday, 1), `@weekday = 1`},
+		{
+			Lt(GeoDistance("location.lon", "location.lat", 23.32, 42.69), 25),
+			`geoDistance(location.lon, location.lat, 23.32, 42.69) < 25`,
+		},
+	}
+	for _, tc := range cases {
+		got, err := tc.expr.Build()
+		if err != nil {
+			t.Errorf("Build() error = %v, want %s", err, tc.want)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Build() = %s, want %s", got, tc.want)
+		}
+	}
+}
+
+func TestInvalidIdentifiers(t *testing.T) {
+	for _, expr := range []Expr{
+		Eq("title = 1 || 1", "x"),
+		Eq("", "x"),
+		Eq("a'b", "x"),
+		Eq(Identifier("@request.auth.id)"), "x"),
+		Lt(GeoDistance("lon;drop", "lat", 1, 2), 3),
+	} {
+		if err := expr.Err(); err == nil {
+			t.Errorf("expected an error for %s", expr)
+		}
+		if got := expr.String(); got != "1 = 0" {
+			t.Errorf("String() = %s, want the always-false condition", got)
+		}
+	}
+}
+
+func TestLogic(t *testing.T) {
+	a := Eq("a", 1)
+	b := Eq("b", 2)
+	c := Eq("c", 3)
+
+	cases := []struct {
+		expr Expr
+		want string
+	}{
+		{a.And(b), `a = 1 && b = 2`},
+		{a.And(b).And(c), `a = 1 && b = 2 && c = 3`},
+		{a.Or(b).And(c), `(a = 1 || b = 2) && c = 3`},
+		{a.And(b).Or(c), `(a = 1 && b = 2) || c = 3`},
+		{And(a, Or(b, c)), `a = 1 && (b = 2 || c = 3)`},
+		{And(Expr{}, a, Expr{}), `a = 1`},
+		{Or(), ``},
+		{a.And(Raw("x = 1 || y = 2", nil)), `a = 1 && (x = 1 || y = 2)`},
+		{a.And(Raw("  ", nil)), `a = 1`},
+	}
+	for _, tc := range cases {
+		if got := tc.expr.String(); got != tc.want {
+			t.Errorf("String() = %s, want %s", got, tc.want)
+		}
+	}
+
+	broken := a.And(Eq("bad field", 1))
+	if broken.Err() == nil {
+		t.Error("Err() should report the invalid nested condition")
+	}
+	if got := broken.String(); got != `a = 1 && 1 = 0` {
+		t.Errorf("String() = %s", got)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		expr   string
+		params map[string]interface{}
+		want   string
+	}{
+		{`title = {:title}`, map[string]interface{}{"title": `it's`}, `title = "it's"`},
+		{`a = {:a} && b = {:b}`, map[string]interface{}{"a": 1, "b": true}, `a = 1 && b = true`},
+		{`a = {:a}`, map[string]interface{}{"a": "{:b}", "b": "x"}, `a = '{:b}'`},
+		{`a = {:missing}`, map[string]interface{}{"a": 1}, `a = {:missing}`},
+		{`a = {:a`, map[string]interface{}{"a": 1}, `a = {:a`},
+		{`a = {:a}`, nil, `a = {:a}`},
+	}
+	for _, tc := range cases {
+		got, err := Format(tc.expr, tc.params)
+		if err != nil {
+			t.Errorf("Format(%q) error = %v", tc.expr, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Format(%q) = %s, want %s", tc.expr, got, tc.want)
+		}
+	}
+
+	if _, err := Format(`a = {:a}`, map[string]interface{}{"a": `x\`}); err == nil {
+		t.Error("Format should fail for a value ending in a backslash")
+	}
+}
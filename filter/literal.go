@@ -0,0 +1,135 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateTimeLayout is the format datetime literals are rendered in.
+const DateTimeLayout = "2006-01-02 15:04:05.000Z"
+
+// Literal renders v as a filter literal. Strings, times and other values are
+// quoted; numbers, booleans and nil are emitted bare.
+func Literal(v interface{}) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "null", nil
+	case string:
+		return Quote(val)
+	case bool:
+		return strconv.FormatBool(val), nil
+	case int:
+		return strconv.FormatInt(int64(val), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(val), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(val), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(val), 10), nil
+	case int64:
+		return strconv.FormatInt(val, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(val), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(val), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(val), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(val), 10), nil
+	case uint64:
+		return strconv.FormatUint(val, 10), nil
+	case float32:
+		return formatFloat(float64(val))
+	case float64:
+		return formatFloat(val)
+	case json.Number:
+		if _, err := val.Float64(); err != nil {
+			return "", fmt.Errorf("filter: invalid number %q", string(val))
+		}
+		return string(val), nil
+	case time.Time:
+		return Quote(val.UTC().Format(DateTimeLayout))
+	case *time.Time:
+		if val == nil {
+			return "null", nil
+		}
+		return Quote(val.UTC().Format(DateTimeLayout))
+	case fmt.Stringer:
+		return Quote(val.String())
+	default:
+		raw, err := json.Marshal(val)
+		if err != nil {
+			return "", fmt.Errorf("filter: cannot encode %T: %w", v, err)
+		}
+		return Quote(string(raw))
+	}
+}
+
+func formatFloat(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("filter: cannot encode %v", f)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64), nil
+}
+
+// Quote wraps s in quotes so it is read back verbatim by the BosBase filter
+// parser. Double quotes are preferred when s contains single quotes only,
+// avoiding escapes entirely; otherwise single quotes inside s are escaped as
+// \'.
+//
+// The parser treats any quote preceded by a backslash as escaped, so a
+// string ending in a backslash cannot be represented. Quote returns an error
+// in that case instead of emitting an unterminated literal.
+func Quote(s string) (string, error) {
+	if strings.HasSuffix(s, `\`) {
+		return "", fmt.Errorf("filter: string literal %q cannot end with a backslash", s)
+	}
+	if strings.Contains(s, "'") && !strings.Contains(s, `"`) {
+		return `"` + s + `"`, nil
+	}
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'", nil
+}
+
+// Format replaces {:name} placeholders in expr with the escaped literal of
+// the matching params value. Placeholders are substituted in a single pass,
+// so values containing placeholder syntax are never expanded again; unknown
+// placeholders are left untouched.
+func Format(expr string, params map[string]interface{}) (string, error) {
+	if len(params) == 0 {
+		return expr, nil
+	}
+	var b strings.Builder
+	b.Grow(len(expr))
+	for {
+		start := strings.Index(expr, "{:")
+		if start < 0 {
+			b.WriteString(expr)
+			break
+		}
+		end := strings.Index(expr[start:], "}")
+		if end < 0 {
+			b.WriteString(expr)
+			break
+		}
+		end += start
+		name := expr[start+2 : end]
+		val, ok := params[name]
+		if !ok {
+			b.WriteString(expr[:end+1])
+			expr = expr[end+1:]
+			continue
+		}
+		lit, err := Literal(val)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(expr[:start])
+		b.WriteString(lit)
+		expr = expr[end+1:]
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,514 @@
+package filter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EvalContext provides the values of @request.* identifiers and the clock of
+// the datetime macros for Match.
+type EvalContext struct {
+	// Now is the reference time of macros such as @now and @todayStart
+	// (defaults to time.Now()). Macros are evaluated in UTC.
+	Now time.Time
+
+	// Auth is the authenticated record (@request.auth.*); nil for guests.
+	Auth    map[string]interface{}
+	Body    map[string]interface{}
+	Query   map[string]string
+	Headers map[string]string
+	Method  string
+	Context string
+}
+
+// ErrUnsupported is returned by Match for constructs that need the database,
+// such as @collection joins.
+var ErrUnsupported = errors.New("filter: not supported client-side")
+
+// Match reports whether record satisfies e. Record fields are resolved from
+// record itself; relation paths such as "author.name" are resolved through
+// the record's "expand" data and are null when not expanded.
+//
+// Multi-value fields follow the server semantics: the plain operators must
+// hold for every item, the "?" operators for at least one.
+func (e Expr) Match(record map[string]interface{}, ctx EvalContext) (bool, error) {
+	if ctx.Now.IsZero() {
+		ctx.Now = time.Now()
+	}
+	return e.match(record, &ctx)
+}
+
+func (e Expr) match(record map[string]interface{}, ctx *EvalContext) (bool, error) {
+	switch {
+	case e.err != nil:
+		return false, e.err
+	case e.logic != "":
+		for _, item := range e.items {
+			ok, err := item.match(record, ctx)
+			if err != nil {
+				return false, err
+			}
+			if e.logic == logicAnd && !ok {
+				return false, nil
+			}
+			if e.logic == logicOr && ok {
+				return true, nil
+			}
+		}
+		return e.logic == logicAnd, nil
+	case e.raw != "":
+		parsed, err := Parse(e.raw)
+		if err != nil {
+			return false, err
+		}
+		return parsed.match(record, ctx)
+	case e.op != "":
+		return e.matchCondition(record, ctx)
+	}
+	// an empty expression matches everything, like an empty filter
+	return true, nil
+}
+
+func (e Expr) matchCondition(record map[string]interface{}, ctx *EvalContext) (bool, error) {
+	left, leftMulti, err := e.left.resolve(record, ctx)
+	if err != nil {
+		return false, err
+	}
+	right, _, err := e.right.resolve(record, ctx)
+	if err != nil {
+		return false, err
+	}
+
+	op := string(e.op)
+	anyOf := strings.HasPrefix(op, "?")
+	op = strings.TrimPrefix(op, "?")
+	if !leftMulti {
+		anyOf = true
+	}
+
+	for _, l := range left {
+		matched := false
+		for _, r := range right {
+			if compareOp(op, l, r) {
+				matched = true
+				break
+			}
+		}
+		if anyOf && matched {
+			return true, nil
+		}
+		if !anyOf && !matched {
+			return false, nil
+		}
+	}
+	return !anyOf, nil
+}
+
+// resolve returns the operand values; multi is set for multi-value fields.
+// An empty multi-value field resolves to a single null item.
+func (o operand) resolve(record map[string]interface{}, ctx *EvalContext) (values []interface{}, multi bool, err error) {
+	switch o.kind {
+	case literalOperand:
+		value, err := o.literal()
+		return []interface{}{value}, false, err
+	case funcOperand:
+		value, err := o.call(record, ctx)
+		return []interface{}{value}, false, err
+	}
+
+	name, modifier := o.name, ""
+	if i := strings.LastIndexByte(name, ':'); i >= 0 && strings.IndexByte(name[i:], '.') < 0 {
+		name, modifier = name[:i], name[i+1:]
+	}
+
+	var value interface{}
+	var isSet bool
+	switch {
+	case strings.HasPrefix(name, "@collection."):
+		return nil, false, fmt.Errorf("%w: %s (@collection joins need the database)", ErrUnsupported, o.name)
+	case strings.HasPrefix(name, "@request."):
+		value, isSet, err = ctx.request(strings.TrimPrefix(name, "@request."))
+		if err != nil {
+			return nil, false, err
+		}
+	case strings.HasPrefix(name, "@"):
+		if modifier != "" {
+			return nil, false, fmt.Errorf("filter: modifier %q cannot be used with %s", modifier, name)
+		}
+		value, err = ctx.macro(name)
+		return []interface{}{value}, false, err
+	default:
+		value, isSet = lookupPath(record, strings.Split(name, "."))
+	}
+
+	switch modifier {
+	case "":
+	case "isset":
+		if !strings.HasPrefix(name, "@request.") {
+			return nil, false, fmt.Errorf("filter: the :isset modifier is only supported for @request.* fields, got %s", o.name)
+		}
+		return []interface{}{isSet}, false, nil
+	case "length":
+		return []interface{}{float64(len(toItems(value)))}, false, nil
+	case "lower":
+		items := toItems(value)
+		for i, item := range items {
+			if s, ok := item.(string); ok {
+				items[i] = strings.ToLower(s)
+			}
+		}
+		if _, ok := value.([]interface{}); ok {
+			return nonEmpty(items), true, nil
+		}
+		return []interface{}{lowerValue(value)}, false, nil
+	case "each":
+		return nonEmpty(toItems(value)), true, nil
+	default:
+		return nil, false, fmt.Errorf("filter: unknown modifier %q in %s", modifier, o.name)
+	}
+
+	if items, ok := value.([]interface{}); ok {
+		return nonEmpty(items), true, nil
+	}
+	return []interface{}{value}, false, nil
+}
+
+// literal returns the normalized value of a literal operand: nil, bool,
+// float64 or string.
+func (o operand) literal() (interface{}, error) {
+	switch v := o.value.(type) {
+	case nil, bool, float64, string:
+		return v, nil
+	}
+	// builder values (ints, times, Stringers...) are read back from their
+	// rendered form
+	tokens, err := tokenize(o.text)
+	if err != nil || len(tokens) != 2 {
+		return nil, fmt.Errorf("filter: invalid literal %s", o.text)
+	}
+	parsed, err := (&parser{tokens: tokens}).parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return parsed.value, nil
+}
+
+func (o operand) call(record map[string]interface{}, ctx *EvalContext) (interface{}, error) {
+	if o.name != "geoDistance" {
+		return nil, fmt.Errorf("%w: function %s", ErrUnsupported, o.name)
+	}
+	if len(o.args) != 4 {
+		return nil, fmt.Errorf("filter: geoDistance expects 4 arguments, got %d", len(o.args))
+	}
+	var coords [4]float64
+	for i, arg := range o.args {
+		values, _, err := arg.resolve(record, ctx)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := toNumber(values[0])
+		if !ok {
+			return nil, nil
+		}
+		coords[i] = f
+	}
+	return haversine(coords[0], coords[1], coords[2], coords[3]), nil
+}
+
+func (ctx *EvalContext) request(path string) (interface{}, bool, error) {
+	section, rest, _ := strings.Cut(path, ".")
+	switch section {
+	case "context":
+		return ctx.Context, ctx.Context != "", nil
+	case "method":
+		return ctx.Method, ctx.Method != "", nil
+	case "auth":
+		if ctx.Auth == nil {
+			return nil, false, nil
+		}
+		value, ok := lookupPath(ctx.Auth, strings.Split(rest, "."))
+		return value, ok, nil
+	case "body":
+		value, ok := lookupPath(ctx.Body, strings.Split(rest, "."))
+		return value, ok, nil
+	case "query":
+		value, ok := ctx.Query[rest]
+		return value, ok, nil
+	case "headers":
+		name := strings.ReplaceAll(strings.ToLower(rest), "-", "_")
+		for key, value := range ctx.Headers {
+			if strings.ReplaceAll(strings.ToLower(key), "-", "_") == name {
+				return value, true, nil
+			}
+		}
+		return nil, false, nil
+	}
+	return nil, false, fmt.Errorf("filter: unknown identifier @request.%s", path)
+}
+
+func (ctx *EvalContext) macro(name string) (interface{}, error) {
+	now := ctx.Now.UTC()
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	const last = time.Millisecond
+
+	var t time.Time
+	switch name {
+	case "@now":
+		t = now
+	case "@second":
+		return float64(now.Second()), nil
+	case "@minute":
+		return float64(now.Minute()), nil
+	case "@hour":
+		return float64(now.Hour()), nil
+	case "@weekday":
+		return float64(now.Weekday()), nil
+	case "@day":
+		return float64(d), nil
+	case "@month":
+		return float64(m), nil
+	case "@year":
+		return float64(y), nil
+	case "@yesterday":
+		t = now.AddDate(0, 0, -1)
+	case "@tomorrow":
+		t = now.AddDate(0, 0, 1)
+	case "@todayStart":
+		t = today
+	case "@todayEnd":
+		t = today.AddDate(0, 0, 1).Add(-last)
+	case "@monthStart":
+		t = time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	case "@monthEnd":
+		t = time.Date(y, m+1, 1, 0, 0, 0, 0, time.UTC).Add(-last)
+	case "@yearStart":
+		t = time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+	case "@yearEnd":
+		t = time.Date(y+1, 1, 1, 0, 0, 0, 0, time.UTC).Add(-last)
+	default:
+		return nil, fmt.Errorf("filter: unknown identifier %s", name)
+	}
+	return t.Format(DateTimeLayout), nil
+}
+
+// lookupPath resolves a dotted field path. Non-object values are looked up
+// in the "expand" data of the record that holds them (relations).
+func lookupPath(record map[string]interface{}, path []string) (interface{}, bool) {
+	if record == nil || len(path) == 0 || path[0] == "" {
+		return nil, false
+	}
+	value, ok := record[path[0]]
+	if len(path) == 1 {
+		return value, ok
+	}
+	if nested, isMap := value.(map[string]interface{}); isMap {
+		return lookupPath(nested, path[1:])
+	}
+	expand, _ := record["expand"].(map[string]interface{})
+	switch related := expand[path[0]].(type) {
+	case map[string]interface{}:
+		return lookupPath(related, path[1:])
+	case []interface{}:
+		var values []interface{}
+		for _, item := range related {
+			if m, ok := item.(map[string]interface{}); ok {
+				v, _ := lookupPath(m, path[1:])
+				values = append(values, toItems(v)...)
+			}
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+func toItems(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return append([]interface{}(nil), v...)
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+		return items
+	case string:
+		if v == "" {
+			return nil
+		}
+	}
+	return []interface{}{value}
+}
+
+func nonEmpty(items []interface{}) []interface{} {
+	if len(items) == 0 {
+		return []interface{}{nil}
+	}
+	return items
+}
+
+func lowerValue(value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		return strings.ToLower(s)
+	}
+	return value
+}
+
+func compareOp(op string, a, b interface{}) bool {
+	switch op {
+	case "=":
+		return equal(a, b)
+	case "!=":
+		return !equal(a, b)
+	case "~":
+		return like(a, b)
+	case "!~":
+		return !like(a, b)
+	}
+	c, ok := order(a, b)
+	if !ok {
+		return false
+	}
+	switch op {
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	}
+	return false
+}
+
+// equal treats null and "" as equal, like the server does.
+func equal(a, b interface{}) bool {
+	if isBlank(a) || isBlank(b) {
+		return isBlank(a) && isBlank(b)
+	}
+	c, ok := order(a, b)
+	return ok && c == 0
+}
+
+func isBlank(v interface{}) bool {
+	s, ok := v.(string)
+	return v == nil || (ok && s == "")
+}
+
+// order compares a and b numerically when both are numbers (bools count as
+// 0 and 1, numeric strings are converted when the other side is a number) and
+// as strings otherwise.
+func order(a, b interface{}) (int, bool) {
+	if a == nil || b == nil {
+		return 0, false
+	}
+	af, aNum := toNumber(a)
+	bf, bNum := toNumber(b)
+	_, aStr := a.(string)
+	_, bStr := b.(string)
+	if aNum && bNum && !(aStr && bStr) {
+		switch {
+		case af < bf:
+			return -1, true
+		case af > bf:
+			return 1, true
+		}
+		return 0, true
+	}
+	return strings.Compare(toText(a), toText(b)), true
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case bool:
+		if n {
+			return 1, true
+		}
+		return 0, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toText(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	}
+	raw, _ := json.Marshal(v)
+	return string(raw)
+}
+
+// like implements the server's case-insensitive LIKE: the pattern is wrapped
+// in % unless it already contains one; \% and \_ match literally.
+func like(value, pattern interface{}) bool {
+	p := toText(pattern)
+	if !strings.Contains(strings.ReplaceAll(p, `\%`, ""), "%") {
+		p = "%" + p + "%"
+	}
+	return likeMatch([]rune(strings.ToLower(toText(value))), []rune(strings.ToLower(p)))
+}
+
+func likeMatch(s, p []rune) bool {
+	for len(p) > 0 {
+		switch {
+		case p[0] == '%':
+			for len(p) > 0 && p[0] == '%' {
+				p = p[1:]
+			}
+			if len(p) == 0 {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if likeMatch(s[i:], p) {
+					return true
+				}
+			}
+			return false
+		case p[0] == '_':
+			if len(s) == 0 {
+				return false
+			}
+		case p[0] == '\\' && len(p) > 1:
+			p = p[1:]
+			fallthrough
+		default:
+			if len(s) == 0 || s[0] != p[0] {
+				return false
+			}
+		}
+		s, p = s[1:], p[1:]
+	}
+	return len(s) == 0
+}
+
+func haversine(lonA, latA, lonB, latB float64) float64 {
+	const earthRadius = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(latB - latA)
+	dLon := toRad(lonB - lonA)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(latA))*math.Cos(toRad(latB))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,185 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	record := map[string]interface{}{
+		"id":       "r1",
+		"title":    "Hello World",
+		"status":   "active",
+		"count":    float64(5),
+		"price":    9.99,
+		"featured": true,
+		"empty":    "",
+		"tags":     []interface{}{"go", "sdk", "Realtime"},
+		"scores":   []interface{}{float64(3), float64(7)},
+		"none":     []interface{}{},
+		"created":  "2024-03-10 08:00:00.000Z",
+		"author":   "u1",
+		"lon":      23.32,
+		"lat":      42.69,
+		"expand": map[string]interface{}{
+			"author": map[string]interface{}{"id": "u1", "name": "Jane"},
+		},
+	}
+	ctx := EvalContext{
+		Now:     time.Date(2024, 3, 10, 12, 30, 0, 0, time.UTC),
+		Auth:    map[string]interface{}{"id": "u1", "role": "editor"},
+		Body:    map[string]interface{}{"title": "New"},
+		Query:   map[string]string{"page": "2"},
+		Headers: map[string]string{"X-Token": "abc"},
+		Method:  "PATCH",
+	}
+
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		// comparison
+		{`status = "active"`, true},
+		{`status != "active"`, false},
+		{`count > 4 && count >= 5 && count < 6 && count <= 5`, true},
+		{`count > 5`, false},
+		{`price = 9.99`, true},
+		{`featured = true`, true},
+		{`featured = false`, false},
+		{`missing = null`, true},
+		{`empty = null`, true},
+		{`empty = ""`, true},
+		{`title = 'hello world'`, false},
+
+		// like
+		{`title ~ "world"`, true},
+		{`title ~ "WORLD"`, true},
+		{`title ~ "Hello%"`, true},
+		{`title ~ "%World"`, true},
+		{`title ~ "H_llo%"`, true},
+		{`title ~ "World%"`, false},
+		{`title !~ "bye"`, true},
+		{`title !~ "hello"`, false},
+
+		// multi-value fields: all items for plain operators, any for "?"
+		{`tags ?= "go"`, true},
+		{`tags = "go"`, false},
+		{`tags != "java"`, true},
+		{`tags ?!= "go"`, true},
+		{`scores ?> 5`, true},
+		{`scores > 5`, false},
+		{`scores > 1`, true},
+		{`scores ?<= 3`, true},
+		{`tags ?~ "real"`, true},
+		{`tags ?!~ "go"`, true},
+		{`none = null`, true},
+
+		// modifiers
+		{`tags:length = 3`, true},
+		{`none:length = 0`, true},
+		{`title:lower = "hello world"`, true},
+		{`tags:lower ?= "realtime"`, true},
+		{`tags:each ~ "o"`, false},
+		{`tags:each != ""`, true},
+		{`@request.body.title:isset = true`, true},
+		{`@request.body.other:isset = true`, false},
+
+		// @request
+		{`author = @request.auth.id`, true},
+		{`@request.auth.role = "editor"`, true},
+		{`@request.auth.id != ""`, true},
+		{`@request.body.title = "New"`, true},
+		{`@request.query.page = "2"`, true},
+		{`@request.headers.x_token = "abc"`, true},
+		{`@request.method = "PATCH"`, true},
+
+		// relations through expand
+		{`author.name = "Jane"`, true},
+		{`author.name = "John"`, false},
+
+		// datetime macros relative to ctx.Now
+		{`created >= @todayStart && created <= @todayEnd`, true},
+		{`created < @now`, true},
+		{`created > @yesterday && created < @tomorrow`, true},
+		{`created >= @monthStart && created <= @monthEnd`, true},
+		{`created >= @yearStart && created <= @yearEnd`, true},
+		{`@hour = 12 && @minute = 30 && @second = 0`, true},
+		{`@day = 10 && @month = 3 && @year = 2024 && @weekday = 0`, true},
+
+		// functions
+		{`geoDistance(lon, lat, 23.32, 42.69) < 0.001`, true},
+		{`geoDistance(lon, lat, 24.75, 42.15) > 100`, true},
+
+		// logic
+		{`status = "draft" || count = 5`, true},
+		{`status = "draft" || count = 5 && featured = false`, false},
+		{`(status = "draft" || count = 5) && featured = true`, true},
+		{``, true},
+	}
+	for _, tc := range cases {
+		expr, err := Parse(tc.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tc.expr, err)
+			continue
+		}
+		got, err := expr.Match(record, ctx)
+		if err != nil {
+			t.Errorf("Match(%q) error = %v", tc.expr, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Match(%q) = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestMatchGuest(t *testing.T) {
+	expr, _ := Parse(`@request.auth.id != ""`)
+	if ok, err := expr.Match(map[string]interface{}{}, EvalContext{}); err != nil || ok {
+		t.Errorf("Match() = %v, %v; guests have no auth id", ok, err)
+	}
+}
+
+func TestMatchBuilderExpr(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	record := map[string]interface{}{"count": float64(3), "created": "2024-01-02 03:04:05.000Z"}
+	expr := Eq("count", 3).And(Eq("created", created), Raw("count < {:max}", map[string]interface{}{"max": 10}))
+	if ok, err := expr.Match(record, EvalContext{}); err != nil || !ok {
+		t.Errorf("Match() = %v, %v", ok, err)
+	}
+}
+
+func TestMatchUnsupported(t *testing.T) {
+	for _, in := range []string{
+		`@collection.users.id ?= author`,
+		`strftime('%Y', created) = "2024"`,
+	} {
+		expr, err := Parse(in)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", in, err)
+		}
+		if _, err := expr.Match(map[string]interface{}{}, EvalContext{}); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Match(%q) error = %v, want ErrUnsupported", in, err)
+		}
+	}
+
+	for _, in := range []string{
+		`title:isset = true`,
+		`title:upper = "X"`,
+		`@unknown = 1`,
+		`@request.unknown = 1`,
+	} {
+		expr, err := Parse(in)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", in, err)
+		}
+		if _, err := expr.Match(map[string]interface{}{}, EvalContext{}); err == nil {
+			t.Errorf("Match(%q) should fail", in)
+		}
+	}
+
+	if _, err := Eq("bad field", 1).Match(nil, EvalContext{}); err == nil {
+		t.Error("Match of an invalid builder expression should fail")
+	}
+}
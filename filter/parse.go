@@ -0,0 +1,261 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse parses a filter expression into an Expr, e.g. to evaluate it with
+// Match. Like the server, && binds tighter than ||.
+func Parse(expr string) (Expr, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return Expr{}, err
+	}
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return Expr{}, nil
+	}
+	result, err := p.parseOr()
+	if err != nil {
+		return Expr{}, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return Expr{}, p.unexpected(tok)
+	}
+	return result, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenFunction
+	tokenText
+	tokenNumber
+	tokenSign
+	tokenJoin
+	tokenOpen
+	tokenClose
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// value is the unquoted text of a text token.
+	value string
+	pos   int
+}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(expr) {
+		ch := expr[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		case strings.HasPrefix(expr[i:], "//"):
+			end := strings.IndexByte(expr[i:], '\n')
+			if end < 0 {
+				i = len(expr)
+			} else {
+				i += end + 1
+			}
+		case ch == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "(", pos: i})
+			i++
+		case ch == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")", pos: i})
+			i++
+		case ch == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+		case strings.HasPrefix(expr[i:], "&&"), strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, token{kind: tokenJoin, text: expr[i : i+2], pos: i})
+			i += 2
+		case ch == '\'' || ch == '"':
+			// like the server, a quote only ends the literal when it is not
+			// preceded by a backslash; backslashes escape nothing else
+			start := i
+			i++
+			for i < len(expr) && (expr[i] != ch || expr[i-1] == '\\') {
+				i++
+			}
+			if i >= len(expr) {
+				return nil, fmt.Errorf("filter: unterminated string literal at position %d", start)
+			}
+			i++
+			text := expr[start:i]
+			value := strings.ReplaceAll(text[1:len(text)-1], `\`+string(ch), string(ch))
+			tokens = append(tokens, token{kind: tokenText, text: text, value: value, pos: start})
+		case isDigit(ch) || (ch == '-' && i+1 < len(expr) && isDigit(expr[i+1])):
+			start := i
+			i++
+			for i < len(expr) && (isDigit(expr[i]) || expr[i] == '.') {
+				i++
+			}
+			text := expr[start:i]
+			if _, err := strconv.ParseFloat(text, 64); err != nil {
+				return nil, fmt.Errorf("filter: invalid number %q at position %d", text, start)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, pos: start})
+		case isIdentifierChar(ch):
+			start := i
+			for i < len(expr) && isIdentifierChar(expr[i]) {
+				i++
+			}
+			kind := tokenIdentifier
+			if i < len(expr) && expr[i] == '(' {
+				kind = tokenFunction
+			}
+			tokens = append(tokens, token{kind: kind, text: expr[start:i], pos: start})
+		case strings.ContainsRune("=!<>~?", rune(ch)):
+			start := i
+			for i < len(expr) && strings.ContainsRune("=!<>~?", rune(expr[i])) {
+				i++
+			}
+			sign := expr[start:i]
+			if !operators[Operator(sign)] {
+				return nil, fmt.Errorf("filter: unknown operator %q at position %d", sign, start)
+			}
+			tokens = append(tokens, token{kind: tokenSign, text: sign, pos: start})
+		default:
+			return nil, fmt.Errorf("filter: unexpected character %q at position %d", ch, i)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(expr)}), nil
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isIdentifierChar(ch byte) bool {
+	return ch == '_' || ch == '@' || ch == '.' || ch == ':' || ch == '#' ||
+		isDigit(ch) || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) unexpected(tok token) error {
+	if tok.kind == tokenEOF {
+		return fmt.Errorf("filter: unexpected end of expression")
+	}
+	return fmt.Errorf("filter: unexpected %q at position %d", tok.text, tok.pos)
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	return p.parseJoin(logicOr, p.parseAnd)
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	return p.parseJoin(logicAnd, p.parseTerm)
+}
+
+func (p *parser) parseJoin(logic string, operand func() (Expr, error)) (Expr, error) {
+	first, err := operand()
+	if err != nil {
+		return Expr{}, err
+	}
+	items := []Expr{first}
+	for tok := p.peek(); tok.kind == tokenJoin && tok.text == logic; tok = p.peek() {
+		p.next()
+		item, err := operand()
+		if err != nil {
+			return Expr{}, err
+		}
+		items = append(items, item)
+	}
+	return join(logic, items), nil
+}
+
+func (p *parser) parseTerm() (Expr, error) {
+	if p.peek().kind == tokenOpen {
+		p.next()
+		if p.peek().kind == tokenClose {
+			return Expr{}, p.unexpected(p.peek())
+		}
+		group, err := p.parseOr()
+		if err != nil {
+			return Expr{}, err
+		}
+		if tok := p.next(); tok.kind != tokenClose {
+			return Expr{}, p.unexpected(tok)
+		}
+		return group, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return Expr{}, err
+	}
+	sign := p.next()
+	if sign.kind != tokenSign {
+		return Expr{}, p.unexpected(sign)
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return Expr{}, err
+	}
+	return Expr{left: left, op: Operator(sign.text), right: right}, nil
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenText:
+		return operand{kind: literalOperand, value: tok.value, text: tok.text}, nil
+	case tokenNumber:
+		f, _ := strconv.ParseFloat(tok.text, 64)
+		return operand{kind: literalOperand, value: f, text: tok.text}, nil
+	case tokenIdentifier:
+		switch tok.text {
+		case "true", "false":
+			return operand{kind: literalOperand, value: tok.text == "true", text: tok.text}, nil
+		case "null":
+			return operand{kind: literalOperand, value: nil, text: tok.text}, nil
+		}
+		return operand{kind: identifierOperand, name: tok.text, text: tok.text}, nil
+	case tokenFunction:
+		fn := operand{kind: funcOperand, name: tok.text}
+		p.next() // (
+		if p.peek().kind == tokenClose {
+			p.next()
+			return fn, nil
+		}
+		for {
+			arg, err := p.parseOperand()
+			if err != nil {
+				return operand{}, err
+			}
+			fn.args = append(fn.args, arg)
+			sep := p.next()
+			if sep.kind == tokenClose {
+				return fn, nil
+			}
+			if sep.kind != tokenComma {
+				return operand{}, p.unexpected(sep)
+			}
+		}
+	}
+	return operand{}, p.unexpected(tok)
+}
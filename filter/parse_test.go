@@ -0,0 +1,130 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{``, ``},
+		{`   `, ``},
+		{`a = 1`, `a = 1`},
+		{`a=1&&b!='x'`, `a = 1 && b != 'x'`},
+		{`a = 1 || b = 2 && c = 3`, `a = 1 || (b = 2 && c = 3)`},
+		{`(a = 1 || b = 2) && c = 3`, `(a = 1 || b = 2) && c = 3`},
+		{`((a = 1))`, `a = 1`},
+		{`tags ?= "go" && tags:length > 2`, `tags ?= "go" && tags:length > 2`},
+		{`@request.auth.id != "" && author = @request.auth.id`, `@request.auth.id != "" && author = @request.auth.id`},
+		{`@request.body.title:isset = true`, `@request.body.title:isset = true`},
+		{`created >= @todayStart`, `created >= @todayStart`},
+		{`n > -1.5`, `n > -1.5`},
+		{`deleted = null || active = false`, `deleted = null || active = false`},
+		{"a = 1 // first\n&& b = 2 // second", `a = 1 && b = 2`},
+		{`geoDistance(lon, lat, 23.32, 42.69) < 25`, `geoDistance(lon, lat, 23.32, 42.69) < 25`},
+		{`@collection.users:u.email ?~ 'x'`, `@collection.users:u.email ?~ 'x'`},
+	}
+	for _, tc := range cases {
+		expr, err := Parse(tc.in)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tc.in, err)
+			continue
+		}
+		if got := expr.String(); got != tc.want {
+			t.Errorf("Parse(%q).String() = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{
+		`a`,
+		`a =`,
+		`= 1`,
+		`a == 1`,
+		`a = 1 &&`,
+		`a = 1 b = 2`,
+		`(a = 1`,
+		`a = 1)`,
+		`()`,
+		`a = 'x`,
+		`a = "x`,
+		`a = 1.2.3`,
+		`a = $`,
+		`a = f(1`,
+		`a = 'a\\'`,
+		`a = '\'`,
+	} {
+		if expr, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %s, want an error", in, expr)
+		}
+	}
+}
+
+func TestParseStringLiterals(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{`''`, ``},
+		{`'plain'`, `plain`},
+		{`"O'Reilly"`, `O'Reilly`},
+		{`'it\'s'`, `it's`},
+		{`"say \"hi\""`, `say "hi"`},
+		{`'a\b'`, `a\b`},
+		{`'a\\b'`, `a\\b`},
+		{`"a\'b"`, `a\'b`},
+		{`'a && b || (c)'`, `a && b || (c)`},
+		{`'żółw 🐢'`, `żółw 🐢`},
+	}
+	for _, tc := range cases {
+		expr, err := Parse("x = " + tc.in)
+		if err != nil {
+			t.Errorf("Parse(%s) error = %v", tc.in, err)
+			continue
+		}
+		if got := expr.right.value; got != tc.want {
+			t.Errorf("Parse(%s) value = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+// Quote output must be read back verbatim by the parser, which follows the
+// server scanner.
+func TestQuoteParseRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		``, `plain`, `O'Reilly`, `say "hi"`, `it's "x"`, `a\b`, `\'`, `"\'`, `\"'`,
+		`a\\b`, `'`, `"`, `''""`, "multi\nline", `// not a comment`, `{:param}`,
+	} {
+		quoted, err := Quote(s)
+		if err != nil {
+			t.Errorf("Quote(%q) error = %v", s, err)
+			continue
+		}
+		expr, err := Parse("x = " + quoted)
+		if err != nil {
+			t.Errorf("Parse(x = %s) error = %v", quoted, err)
+			continue
+		}
+		if got := expr.right.value; got != s {
+			t.Errorf("round trip of %q via %s = %q", s, quoted, got)
+		}
+	}
+}
+
+func TestParsedAndBuiltExprsAgree(t *testing.T) {
+	built := Eq("status", "it's").And(Gt(Field("tags").Length(), 2).Or(AnyLike("tags", `a\b`)))
+	parsed, err := Parse(built.String())
+	if err != nil {
+		t.Fatalf("Parse(%s) error = %v", built, err)
+	}
+	if parsed.String() != built.String() {
+		t.Errorf("parsed %s, built %s", parsed, built)
+	}
+	if !strings.Contains(built.String(), `"it's"`) {
+		t.Errorf("unexpected rendering %s", built)
+	}
+}
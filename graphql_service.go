@@ -1,6 +1,7 @@
 package bosbase
 
 import (
+    "context"
     "net/http"
     "time"
 )
@@ -14,6 +15,10 @@ func NewGraphQLService(client *BosBase) *GraphQLService {
 }
 
 func (s *GraphQLService) Query(query string, variables map[string]interface{}, operationName string, queryParams map[string]interface{}, headers map[string]string, timeout time.Duration) (map[string]interface{}, error) {
+    return s.QueryCtx(context.Background(), query, variables, operationName, queryParams, headers, timeout)
+}
+
+func (s *GraphQLService) QueryCtx(ctx context.Context, query string, variables map[string]interface{}, operationName string, queryParams map[string]interface{}, headers map[string]string, timeout time.Duration) (map[string]interface{}, error) {
     payload := map[string]interface{}{
         "query":     query,
         "variables": map[string]interface{}{},
@@ -24,7 +29,7 @@ func (s *GraphQLService) Query(query string, variables map[string]interface{}, o
     if operationName != "" {
         payload["operationName"] = operationName
     }
-    data, err := s.client.Send("/api/graphql", &RequestOptions{Method: http.MethodPost, Body: payload, Query: queryParams, Headers: headers, Timeout: timeout})
+    data, err := s.client.SendContext(ctx, "/api/graphql", &RequestOptions{Method: http.MethodPost, Body: payload, Query: queryParams, Headers: headers, Timeout: timeout})
     if err != nil {
         return nil, err
     }
@@ -1,5 +1,7 @@
 package bosbase
 
+import "context"
+
 // HealthService exposes health checks.
 type HealthService struct {
     BaseService
@@ -10,7 +12,11 @@ func NewHealthService(client *BosBase) *HealthService {
 }
 
 func (s *HealthService) Check(query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
-    data, err := s.client.Send("/api/health", &RequestOptions{Query: query, Headers: headers})
+    return s.CheckCtx(context.Background(), query, headers)
+}
+
+func (s *HealthService) CheckCtx(ctx context.Context, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    data, err := s.client.SendContext(ctx, "/api/health", &RequestOptions{Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
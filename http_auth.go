@@ -0,0 +1,250 @@
+package bosbase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuthMiddlewareOptions configures AuthMiddleware.
+type AuthMiddlewareOptions struct {
+	// CookieName is the auth cookie (in the ExportToCookie format) checked
+	// when the request has no Authorization header. It defaults to
+	// DefaultAuthCookieName.
+	CookieName string
+	// DisableCookie only accepts tokens from the Authorization header.
+	DisableCookie bool
+	// Collections restricts access to auth records of the listed collections
+	// (names or ids). Empty allows any auth collection.
+	Collections []string
+	// SuperuserOnly restricts access to superusers. It takes precedence over
+	// Collections.
+	SuperuserOnly bool
+	// CacheTTL caches successful validations per token for the given
+	// duration (capped by the token expiry). Zero validates every request
+	// against the server with auth-refresh.
+	CacheTTL time.Duration
+	// OnReject writes the response for rejected requests. err is a
+	// *ClientResponseError with Status 401 (missing or invalid token),
+	// 403 (not allowed) or 503 (the server could not be reached). It
+	// defaults to a JSON error response in the BosBase format.
+	OnReject func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type authRecordKey struct{}
+
+type authClientKey struct{}
+
+// RecordFromContext returns the auth record stored by AuthMiddleware.
+func RecordFromContext(ctx context.Context) (Record, bool) {
+	record, ok := ctx.Value(authRecordKey{}).(Record)
+	return record, ok
+}
+
+// ClientFromContext returns the per-request client stored by AuthMiddleware.
+// It is a clone of the middleware client authenticated as the caller, so
+// requests made with it are subject to the caller's API rules.
+func ClientFromContext(ctx context.Context) (*BosBase, bool) {
+	client, ok := ctx.Value(authClientKey{}).(*BosBase)
+	return client, ok
+}
+
+// AuthMiddleware authenticates incoming requests carrying a BosBase auth
+// token, for Go backends that sit next to BosBase:
+//
+//	mux.Handle("/api/orders", bosbase.AuthMiddleware(client, bosbase.AuthMiddlewareOptions{
+//		Collections: []string{"users"},
+//		CacheTTL:    time.Minute,
+//	})(ordersHandler))
+//
+// The token is read from the Authorization header (with or without the
+// "Bearer " prefix) or from the auth cookie, and validated with auth-refresh
+// against the server. The returned record and a client authenticated with
+// the token are available to the handler through RecordFromContext and
+// ClientFromContext. client's own AuthStore is never modified.
+func AuthMiddleware(client *BosBase, opts AuthMiddlewareOptions) func(http.Handler) http.Handler {
+	if opts.CookieName == "" {
+		opts.CookieName = DefaultAuthCookieName
+	}
+	reject := opts.OnReject
+	if reject == nil {
+		reject = writeAuthRejection
+	}
+	var cache *authCache
+	if opts.CacheTTL > 0 {
+		cache = &authCache{ttl: opts.CacheTTL, entries: make(map[string]authCacheEntry)}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := requestAuthToken(r, opts)
+			if token == "" {
+				reject(w, r, newClientError(r.URL.String(), http.StatusUnauthorized, "The request requires valid record authorization token."))
+				return
+			}
+
+			record, err := validateAuthToken(r.Context(), client, cache, token)
+			if err != nil {
+				reject(w, r, err)
+				return
+			}
+
+			store := NewAuthStore()
+			store.Save(token, record)
+			if !opts.allows(store) {
+				reject(w, r, newClientError(r.URL.String(), http.StatusForbidden, "The authorized record is not allowed to perform this action."))
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), authRecordKey{}, record)
+			ctx = context.WithValue(ctx, authClientKey{}, client.WithAuth(token, record))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func (o AuthMiddlewareOptions) allows(store *AuthStore) bool {
+	if o.SuperuserOnly {
+		return store.IsSuperuser()
+	}
+	if len(o.Collections) == 0 {
+		return true
+	}
+	for _, name := range o.Collections {
+		if store.IsAuthCollection(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func requestAuthToken(r *http.Request, opts AuthMiddlewareOptions) string {
+	if header := strings.TrimSpace(r.Header.Get("Authorization")); header != "" {
+		if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+			header = strings.TrimSpace(header[7:])
+		}
+		return header
+	}
+	if opts.DisableCookie {
+		return ""
+	}
+	store := NewAuthStore()
+	if err := LoadAuthCookie(store, r, opts.CookieName); err != nil {
+		return ""
+	}
+	return store.Token()
+}
+
+// validateAuthToken checks token with auth-refresh on a throwaway client, so
+// neither client nor the cache is touched for invalid tokens.
+func validateAuthToken(ctx context.Context, client *BosBase, cache *authCache, token string) (Record, error) {
+	claims, err := ParseTokenClaims(token)
+	if err != nil || claims.Type != "auth" || claims.CollectionID == "" || !claims.ExpiresAt.After(time.Now()) {
+		return nil, newClientError("", http.StatusUnauthorized, "The request requires valid record authorization token.")
+	}
+	if record, ok := cache.get(token); ok {
+		return record, nil
+	}
+
+	data, err := client.WithAuth(token, nil).Collection(claims.CollectionID).AuthRefreshCtx(ctx, "", "", nil, nil, nil)
+	if err != nil {
+		var cre *ClientResponseError
+		if errors.As(err, &cre) && (cre.Status == http.StatusUnauthorized || cre.Status == http.StatusForbidden || cre.Status == http.StatusNotFound) {
+			return nil, newClientError(cre.URL, http.StatusUnauthorized, "The request requires valid record authorization token.")
+		}
+		rejection := newClientError("", http.StatusServiceUnavailable, "Failed to validate the authorization token.")
+		rejection.OriginalErr = err
+		return nil, rejection
+	}
+	record, _ := data["record"].(map[string]interface{})
+	if record == nil {
+		return nil, newClientError("", http.StatusUnauthorized, "The request requires valid record authorization token.")
+	}
+
+	cache.set(token, Record(record), claims.ExpiresAt)
+	return Record(record), nil
+}
+
+func writeAuthRejection(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusUnauthorized
+	message := err.Error()
+	var cre *ClientResponseError
+	if errors.As(err, &cre) {
+		status = cre.Status
+		message = cre.Message()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  status,
+		"message": message,
+		"data":    map[string]interface{}{},
+	})
+}
+
+// authCache is a TTL cache of validated tokens. A nil *authCache is a no-op.
+type authCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]authCacheEntry
+}
+
+type authCacheEntry struct {
+	record  Record
+	expires time.Time
+}
+
+// maxAuthCacheEntries bounds the cache; reaching it sweeps expired entries
+// and, if needed, evicts the entry expiring first.
+const maxAuthCacheEntries = 1024
+
+func (c *authCache) get(token string) (Record, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[token]
+	if !ok {
+		return nil, false
+	}
+	if !time.Now().Before(entry.expires) {
+		delete(c.entries, token)
+		return nil, false
+	}
+	// handlers get their own copy to mutate
+	return Record(cloneQuery(entry.record)), true
+}
+
+func (c *authCache) set(token string, record Record, notAfter time.Time) {
+	if c == nil {
+		return
+	}
+	now := time.Now()
+	expires := now.Add(c.ttl)
+	if notAfter.Before(expires) {
+		expires = notAfter
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[token]; !ok && len(c.entries) >= maxAuthCacheEntries {
+		oldest := ""
+		for key, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, key)
+			} else if oldest == "" || entry.expires.Before(c.entries[oldest].expires) {
+				oldest = key
+			}
+		}
+		// still full with valid tokens: evict the one expiring first
+		if len(c.entries) >= maxAuthCacheEntries {
+			delete(c.entries, oldest)
+		}
+	}
+	c.entries[token] = authCacheEntry{record: Record(cloneQuery(record)), expires: expires}
+}
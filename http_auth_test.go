@@ -0,0 +1,106 @@
+package bosbase
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAuthCacheIsBounded(t *testing.T) {
+	cache := &authCache{ttl: time.Hour, entries: make(map[string]authCacheEntry)}
+	now := time.Now()
+	for i := 0; i < maxAuthCacheEntries*2; i++ {
+		// later tokens expire later
+		cache.set(fmt.Sprintf("token-%d", i), Record{"id": "u"}, now.Add(time.Minute+time.Duration(i)*time.Millisecond))
+	}
+	if got := len(cache.entries); got != maxAuthCacheEntries {
+		t.Fatalf("cache holds %d entries, want %d", got, maxAuthCacheEntries)
+	}
+	if _, ok := cache.get("token-0"); ok {
+		t.Error("the entry expiring first should have been evicted")
+	}
+	if _, ok := cache.get(fmt.Sprintf("token-%d", maxAuthCacheEntries*2-1)); !ok {
+		t.Error("the newest entry should be cached")
+	}
+
+	// updating a cached token never evicts another one
+	cache.set(fmt.Sprintf("token-%d", maxAuthCacheEntries*2-1), Record{"id": "u"}, now.Add(time.Hour))
+	if got := len(cache.entries); got != maxAuthCacheEntries {
+		t.Errorf("cache holds %d entries after an update", got)
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	var refreshes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&refreshes, 1)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") == "" || r.URL.Path != "/api/collections/c1/auth-refresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":401,"message":"Unauthorized."}`))
+			return
+		}
+		fmt.Fprintf(w, `{"token":%q,"record":{"id":"u1","collectionId":"c1","collectionName":"users"}}`, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	handler := func(opts AuthMiddlewareOptions) http.Handler {
+		return AuthMiddleware(New(server.URL), opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			record, _ := RecordFromContext(r.Context())
+			client, _ := ClientFromContext(r.Context())
+			fmt.Fprintf(w, "%s %t", record.ID(), client != nil && client.AuthStore.Token() != "")
+		}))
+	}
+	token := testToken(time.Now().Add(time.Hour), "")
+
+	cases := []struct {
+		name   string
+		opts   AuthMiddlewareOptions
+		header string
+		want   int
+	}{
+		{"missing token", AuthMiddlewareOptions{}, "", http.StatusUnauthorized},
+		{"malformed token", AuthMiddlewareOptions{}, "nope", http.StatusUnauthorized},
+		{"expired token", AuthMiddlewareOptions{}, testToken(time.Now().Add(-time.Minute), ""), http.StatusUnauthorized},
+		{"valid token", AuthMiddlewareOptions{}, "Bearer " + token, http.StatusOK},
+		{"allowed collection", AuthMiddlewareOptions{Collections: []string{"users"}}, token, http.StatusOK},
+		{"other collection", AuthMiddlewareOptions{Collections: []string{"admins"}}, token, http.StatusForbidden},
+		{"superuser only", AuthMiddlewareOptions{SuperuserOnly: true}, token, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rec := httptest.NewRecorder()
+			handler(tc.opts).ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tc.want, rec.Body)
+			}
+			if tc.want == http.StatusOK && rec.Body.String() != "u1 true" {
+				t.Errorf("body = %q", rec.Body)
+			}
+		})
+	}
+
+	t.Run("cache", func(t *testing.T) {
+		h := handler(AuthMiddlewareOptions{CacheTTL: time.Minute})
+		before := atomic.LoadInt32(&refreshes)
+		for i := 0; i < 3; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", token)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d", rec.Code)
+			}
+		}
+		if got := atomic.LoadInt32(&refreshes) - before; got != 1 {
+			t.Errorf("validated %d times, want 1", got)
+		}
+	})
+}
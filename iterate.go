@@ -0,0 +1,206 @@
+package bosbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bosbase/go-sdk/filter"
+)
+
+// IterateOptions configures RecordService.Iterate.
+type IterateOptions struct {
+	// PerPage is the page size (defaults to 200).
+	PerPage int
+	Filter  string
+	// Sort orders the records. In keyset mode it must be a single field of
+	// the collection itself (no relation path or modifier), optionally
+	// prefixed with "-", and defaults to "id".
+	Sort    string
+	Expand  string
+	Fields  string
+	Query   map[string]interface{}
+	Headers map[string]string
+	// Keyset pages by the last seen (sort field, id) pair using filters
+	// instead of page numbers. Unlike offset paging it never skips or repeats
+	// records when the collection changes during the iteration.
+	Keyset bool
+}
+
+// RecordIterator lazily fetches records page by page.
+//
+//	it := client.Collection("posts").Iterate(ctx, &bosbase.IterateOptions{Keyset: true})
+//	for it.Next() {
+//		record := it.Item()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type RecordIterator struct {
+	ctx     context.Context
+	service *RecordService
+	opts    IterateOptions
+
+	sortField string
+	desc      bool
+
+	page    int
+	buf     []Record
+	item    Record
+	err     error
+	done    bool
+	lastKey interface{}
+	lastID  string
+}
+
+// Iterate returns an iterator over the records matching opts. Pages are
+// requested only as the iterator advances, so memory use is bounded by
+// PerPage regardless of the collection size.
+func (s *RecordService) Iterate(ctx context.Context, opts *IterateOptions) *RecordIterator {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	it := &RecordIterator{ctx: ctx, service: s, page: 1}
+	if opts != nil {
+		it.opts = *opts
+	}
+	if it.opts.PerPage <= 0 {
+		it.opts.PerPage = 200
+	}
+	if it.opts.Keyset {
+		sortField := strings.TrimSpace(it.opts.Sort)
+		if sortField == "" {
+			sortField = "id"
+		}
+		if strings.HasPrefix(sortField, "-") {
+			it.desc = true
+			sortField = sortField[1:]
+		} else {
+			sortField = strings.TrimPrefix(sortField, "+")
+		}
+		switch {
+		case sortField == "" || strings.ContainsAny(sortField, ", "):
+			it.err = errors.New("keyset iteration requires Sort to be a single field")
+			it.done = true
+		case strings.ContainsAny(sortField, ".:@"):
+			// the cursor is read from the returned records, which hold no
+			// value for relation paths, modifiers or macros like @random
+			it.err = fmt.Errorf("keyset iteration cannot sort by %q, use a field of the collection", sortField)
+			it.done = true
+		}
+		it.sortField = sortField
+	}
+	return it
+}
+
+// Next advances to the next record, fetching a new page when needed. It
+// returns false when the records are exhausted or an error occurred.
+func (it *RecordIterator) Next() bool {
+	for len(it.buf) == 0 {
+		if it.done {
+			it.item = nil
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			it.done = true
+			it.item = nil
+			return false
+		}
+	}
+	it.item = it.buf[0]
+	it.buf = it.buf[1:]
+	return true
+}
+
+// Item returns the current record.
+func (it *RecordIterator) Item() Record {
+	return it.item
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *RecordIterator) Err() error {
+	return it.err
+}
+
+func (it *RecordIterator) fetch() error {
+	if err := it.ctx.Err(); err != nil {
+		return newAbortError(it.service.client.BuildURL(it.service.basePath(), nil), err)
+	}
+	listOpts := &CrudListOptions{
+		Page:      it.page,
+		PerPage:   it.opts.PerPage,
+		SkipTotal: true,
+		Filter:    it.opts.Filter,
+		Sort:      it.opts.Sort,
+		Expand:    it.opts.Expand,
+		Fields:    it.opts.Fields,
+		Query:     it.opts.Query,
+		Headers:   it.opts.Headers,
+	}
+	if it.opts.Keyset {
+		sort, where, err := it.keysetQuery()
+		if err != nil {
+			return err
+		}
+		listOpts.Page = 1
+		listOpts.Sort = sort
+		listOpts.Filter = where
+		if listOpts.Fields != "" && listOpts.Fields != "*" {
+			// the cursor needs the sort field and id of every record
+			listOpts.Fields += "," + it.sortField + ",id"
+		}
+	}
+
+	data, err := it.service.GetListCtx(it.ctx, listOpts)
+	if err != nil {
+		return err
+	}
+	items, _ := data["items"].([]interface{})
+	it.buf = toRecords(items)
+	it.page++
+	if len(items) < it.opts.PerPage {
+		it.done = true
+	}
+	if it.opts.Keyset && len(it.buf) > 0 {
+		last := it.buf[len(it.buf)-1]
+		it.lastKey = last[it.sortField]
+		it.lastID = last.ID()
+	}
+	return nil
+}
+
+// keysetQuery returns the sort and filter of the next keyset page.
+func (it *RecordIterator) keysetQuery() (string, string, error) {
+	sort := it.sortField
+	if it.sortField != "id" {
+		sort += ",id"
+	}
+	if it.desc {
+		sort = "-" + strings.ReplaceAll(sort, ",", ",-")
+	}
+
+	where := filter.Raw(it.opts.Filter, nil)
+	if it.lastID != "" {
+		after := filter.Gt
+		if it.desc {
+			after = filter.Lt
+		}
+		var cursor filter.Expr
+		if it.sortField == "id" {
+			cursor = after("id", it.lastID)
+		} else {
+			cursor = after(it.sortField, it.lastKey).Or(
+				filter.Eq(it.sortField, it.lastKey).And(after("id", it.lastID)),
+			)
+		}
+		where = where.And(cursor)
+	}
+	filterExpr, err := where.Build()
+	if err != nil {
+		return "", "", &ClientResponseError{OriginalErr: err}
+	}
+	return sort, filterExpr, nil
+}
@@ -0,0 +1,27 @@
+//go:build go1.23
+
+package bosbase
+
+import "iter"
+
+// All returns a range-over-func sequence of the remaining records. A non-nil
+// error is yielded once, as the last element, when the iteration fails.
+//
+//	for record, err := range client.Collection("posts").Iterate(ctx, nil).All() {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (it *RecordIterator) All() iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		for it.Next() {
+			if !yield(it.Item(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
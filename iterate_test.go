@@ -0,0 +1,68 @@
+package bosbase
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestIterateKeyset(t *testing.T) {
+	var filters []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		filters = append(filters, query.Get("filter"))
+		if got := query.Get("sort"); got != "-created,-id" {
+			t.Errorf("sort = %q", got)
+		}
+		perPage, _ := strconv.Atoi(query.Get("perPage"))
+		items := []map[string]interface{}{}
+		start := len(filters)*perPage - perPage
+		for i := start; i < start+perPage && i < 5; i++ {
+			items = append(items, map[string]interface{}{
+				"id":      "r" + strconv.Itoa(i),
+				"created": "2024-01-0" + strconv.Itoa(9-i),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	}))
+	defer server.Close()
+
+	client := New(server.URL)
+	// a nil ctx is treated as context.Background()
+	it := client.Collection("posts").Iterate(nil, &IterateOptions{PerPage: 2, Sort: "-created", Keyset: true})
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Item().ID())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	if got := strings.Join(ids, ","); got != "r0,r1,r2,r3,r4" {
+		t.Errorf("ids = %s", got)
+	}
+	want := []string{
+		"",
+		"created < '2024-01-08' || (created = '2024-01-08' && id < 'r1')",
+		"created < '2024-01-06' || (created = '2024-01-06' && id < 'r3')",
+	}
+	if strings.Join(filters, "\n") != strings.Join(want, "\n") {
+		t.Errorf("filters = %q, want %q", filters, want)
+	}
+}
+
+func TestIterateKeysetRejectsUnsupportedSort(t *testing.T) {
+	client := New("http://127.0.0.1:0")
+	for _, sort := range []string{"author.name", "-author.created", "title:lower", "@random", "a,b"} {
+		it := client.Collection("posts").Iterate(nil, &IterateOptions{Sort: sort, Keyset: true})
+		if it.Next() {
+			t.Errorf("Next() with sort %q should fail", sort)
+		}
+		if it.Err() == nil {
+			t.Errorf("Err() with sort %q should report the invalid sort", sort)
+		}
+	}
+}
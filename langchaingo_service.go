@@ -1,6 +1,9 @@
 package bosbase
 
-import "net/http"
+import (
+    "context"
+    "net/http"
+)
 
 type LangChaingoService struct {
     BaseService
@@ -12,7 +15,11 @@ func NewLangChaingoService(client *BosBase) *LangChaingoService {
 }
 
 func (s *LangChaingoService) Completions(req LangChaingoCompletionRequest, query map[string]string, headers map[string]string) (LangChaingoCompletionResponse, error) {
-    data, err := s.client.Send(s.basePath+"/completions", &RequestOptions{Method: http.MethodPost, Body: req.ToMap(), Query: toAnyMap(query), Headers: headers})
+    return s.CompletionsCtx(context.Background(), req, query, headers)
+}
+
+func (s *LangChaingoService) CompletionsCtx(ctx context.Context, req LangChaingoCompletionRequest, query map[string]string, headers map[string]string) (LangChaingoCompletionResponse, error) {
+    data, err := s.client.SendContext(ctx, s.basePath+"/completions", &RequestOptions{Method: http.MethodPost, Body: req.ToMap(), Query: toAnyMap(query), Headers: headers})
     if err != nil {
         return LangChaingoCompletionResponse{}, err
     }
@@ -23,7 +30,11 @@ func (s *LangChaingoService) Completions(req LangChaingoCompletionRequest, query
 }
 
 func (s *LangChaingoService) RAG(req LangChaingoRAGRequest, query map[string]string, headers map[string]string) (LangChaingoRAGResponse, error) {
-    data, err := s.client.Send(s.basePath+"/rag", &RequestOptions{Method: http.MethodPost, Body: req.ToMap(), Query: toAnyMap(query), Headers: headers})
+    return s.RAGCtx(context.Background(), req, query, headers)
+}
+
+func (s *LangChaingoService) RAGCtx(ctx context.Context, req LangChaingoRAGRequest, query map[string]string, headers map[string]string) (LangChaingoRAGResponse, error) {
+    data, err := s.client.SendContext(ctx, s.basePath+"/rag", &RequestOptions{Method: http.MethodPost, Body: req.ToMap(), Query: toAnyMap(query), Headers: headers})
     if err != nil {
         return LangChaingoRAGResponse{}, err
     }
@@ -34,7 +45,11 @@ func (s *LangChaingoService) RAG(req LangChaingoRAGRequest, query map[string]str
 }
 
 func (s *LangChaingoService) QueryDocuments(req LangChaingoRAGRequest, query map[string]string, headers map[string]string) (LangChaingoRAGResponse, error) {
-    data, err := s.client.Send(s.basePath+"/documents/query", &RequestOptions{Method: http.MethodPost, Body: req.ToMap(), Query: toAnyMap(query), Headers: headers})
+    return s.QueryDocumentsCtx(context.Background(), req, query, headers)
+}
+
+func (s *LangChaingoService) QueryDocumentsCtx(ctx context.Context, req LangChaingoRAGRequest, query map[string]string, headers map[string]string) (LangChaingoRAGResponse, error) {
+    data, err := s.client.SendContext(ctx, s.basePath+"/documents/query", &RequestOptions{Method: http.MethodPost, Body: req.ToMap(), Query: toAnyMap(query), Headers: headers})
     if err != nil {
         return LangChaingoRAGResponse{}, err
     }
@@ -45,7 +60,11 @@ func (s *LangChaingoService) QueryDocuments(req LangChaingoRAGRequest, query map
 }
 
 func (s *LangChaingoService) SQL(req LangChaingoSQLRequest, query map[string]string, headers map[string]string) (LangChaingoSQLResponse, error) {
-    data, err := s.client.Send(s.basePath+"/sql", &RequestOptions{Method: http.MethodPost, Body: req.ToMap(), Query: toAnyMap(query), Headers: headers})
+    return s.SQLCtx(context.Background(), req, query, headers)
+}
+
+func (s *LangChaingoService) SQLCtx(ctx context.Context, req LangChaingoSQLRequest, query map[string]string, headers map[string]string) (LangChaingoSQLResponse, error) {
+    data, err := s.client.SendContext(ctx, s.basePath+"/sql", &RequestOptions{Method: http.MethodPost, Body: req.ToMap(), Query: toAnyMap(query), Headers: headers})
     if err != nil {
         return LangChaingoSQLResponse{}, err
     }
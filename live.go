@@ -0,0 +1,503 @@
+package bosbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bosbase/go-sdk/filter"
+)
+
+// LiveOptions configures RecordService.Live.
+type LiveOptions struct {
+	// Filter selects the records of the list. Realtime events are matched
+	// against it client-side, resolving relation fields from the expanded
+	// data, so relations used in the filter should be listed in Expand.
+	Filter string
+	// Sort is kept locally when applying realtime events. Plain fields with
+	// an optional "-" or "+" prefix are supported; e.g. "-created,title".
+	Sort    string
+	Expand  string
+	Fields  string
+	Query   map[string]interface{}
+	Headers map[string]string
+	// BatchSize is the page size used to load the list (defaults to 500).
+	BatchSize int
+	// OnChange is called after every change of the list, including the
+	// initial load, from a single goroutine.
+	OnChange func(LiveDiff)
+}
+
+// LiveDiff describes a change of a LiveList.
+type LiveDiff struct {
+	Created []Record
+	Updated []Record
+	Deleted []Record
+	// Resync is true when the diff results from a full reload after a
+	// realtime reconnect.
+	Resync bool
+}
+
+// IsEmpty reports whether the diff contains no changes.
+func (d LiveDiff) IsEmpty() bool {
+	return len(d.Created) == 0 && len(d.Updated) == 0 && len(d.Deleted) == 0
+}
+
+// LiveList is an in-memory replica of a filtered collection kept in sync by
+// realtime events. It is safe for concurrent use; the returned records are
+// shared snapshots and must not be modified.
+type LiveList struct {
+	service *RecordService
+	opts    LiveOptions
+	order   []liveSortField
+	// match is the parsed Filter, nil when the filter couldn't be parsed
+	// client-side and every event needs a record lookup.
+	match *filter.Expr
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu    sync.RWMutex
+	items []Record
+	err   error
+
+	queueMu sync.Mutex
+	queue   []liveEvent
+	signal  chan struct{}
+}
+
+type liveEvent struct {
+	action RecordAction
+	record Record
+	resync bool
+}
+
+type liveSortField struct {
+	name string
+	desc bool
+}
+
+// Live loads the records matching opts and keeps them in sync with realtime
+// create/update/delete events until ctx is done. Events received during the
+// initial load are buffered and applied in order afterwards, and the list is
+// reloaded after every realtime reconnect since events may have been missed.
+//
+// With a Filter, create and update events are matched locally (see
+// filter.Expr.Match), so records that stop matching are removed from the
+// list. Filters the client cannot evaluate, such as @collection joins, are
+// checked with a record lookup per event instead.
+func (s *RecordService) Live(ctx context.Context, opts LiveOptions) (*LiveList, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	l := &LiveList{
+		service: s,
+		opts:    opts,
+		order:   parseLiveSort(opts.Sort),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		signal:  make(chan struct{}, 1),
+	}
+	if opts.Filter != "" {
+		if expr, err := filter.Parse(opts.Filter); err == nil {
+			l.match = &expr
+		}
+	}
+
+	// the filter is evaluated per event (see above), so only the record
+	// shape options are sent with the subscription
+	query := cloneQuery(opts.Query)
+	if opts.Expand != "" {
+		query["expand"] = opts.Expand
+	}
+	if opts.Fields != "" {
+		query["fields"] = opts.Fields
+	}
+	realtime := s.client.Realtime
+	unsubscribe, err := realtime.subscribeCtx(ctx, s.collection+"/*", l.enqueue, query, opts.Headers)
+	if err != nil {
+		cancel()
+		if unsubscribe != nil {
+			unsubscribe()
+		}
+		return nil, err
+	}
+	removeConnect := realtime.onConnect(func() {
+		l.push(liveEvent{resync: true})
+	})
+
+	items, err := l.load()
+	if err != nil {
+		removeConnect()
+		unsubscribe()
+		cancel()
+		return nil, err
+	}
+	l.items = items
+
+	go l.run(func() {
+		removeConnect()
+		unsubscribe()
+	}, LiveDiff{Created: append([]Record(nil), items...)})
+	return l, nil
+}
+
+// Items returns a snapshot of the records in sort order.
+func (l *LiveList) Items() []Record {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]Record(nil), l.items...)
+}
+
+// Len returns the number of records.
+func (l *LiveList) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.items)
+}
+
+// Get returns the record with the given id.
+func (l *LiveList) Get(id string) (Record, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if i := l.indexOf(id); i >= 0 {
+		return l.items[i], true
+	}
+	return nil, false
+}
+
+// Err returns the last error of applying an event or reloading the list.
+// The list keeps following realtime events and retries the reload on the
+// next reconnect.
+func (l *LiveList) Err() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.err
+}
+
+// Close stops following realtime events. Cancelling the ctx passed to Live
+// has the same effect.
+func (l *LiveList) Close() {
+	l.cancel()
+}
+
+// Done is closed once the list stopped following realtime events.
+func (l *LiveList) Done() <-chan struct{} {
+	return l.done
+}
+
+// enqueue runs on the realtime reader goroutine and must not block.
+func (l *LiveList) enqueue(payload map[string]interface{}) {
+	event := liveEvent{}
+	if action, ok := payload["action"].(string); ok {
+		event.action = RecordAction(action)
+	}
+	record, _ := payload["record"].(map[string]interface{})
+	if record == nil {
+		return
+	}
+	event.record = Record(record)
+	l.push(event)
+}
+
+func (l *LiveList) push(event liveEvent) {
+	l.queueMu.Lock()
+	l.queue = append(l.queue, event)
+	l.queueMu.Unlock()
+	select {
+	case l.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (l *LiveList) run(stop func(), initial LiveDiff) {
+	defer close(l.done)
+	defer stop()
+
+	l.notify(initial)
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-l.signal:
+		}
+		l.queueMu.Lock()
+		events := l.queue
+		l.queue = nil
+		l.queueMu.Unlock()
+
+		for _, event := range events {
+			if l.ctx.Err() != nil {
+				return
+			}
+			l.notify(l.apply(event))
+		}
+	}
+}
+
+func (l *LiveList) apply(event liveEvent) LiveDiff {
+	if event.resync {
+		items, err := l.load()
+		if err != nil {
+			l.fail(err)
+			return LiveDiff{}
+		}
+		return l.replace(items)
+	}
+
+	id := event.record.ID()
+	if id == "" {
+		return LiveDiff{}
+	}
+	record := event.record
+	if event.action != ActionDelete && l.opts.Filter != "" {
+		current, ok, err := l.member(record)
+		if err != nil {
+			l.fail(err)
+			return LiveDiff{}
+		}
+		if !ok {
+			return l.remove(id)
+		}
+		record = current
+	}
+
+	switch event.action {
+	case ActionDelete:
+		return l.remove(id)
+	case ActionCreate, ActionUpdate:
+		return l.upsert(record)
+	}
+	return LiveDiff{}
+}
+
+func (l *LiveList) load() ([]Record, error) {
+	return l.service.GetFullRecordList(l.ctx, l.opts.BatchSize, &CrudListOptions{
+		Filter:  l.opts.Filter,
+		Sort:    l.opts.Sort,
+		Expand:  l.opts.Expand,
+		Fields:  l.opts.Fields,
+		Query:   l.opts.Query,
+		Headers: l.opts.Headers,
+	})
+}
+
+// member reports whether record matches the filter, evaluating it locally
+// when possible. The returned record is the one to keep, which is re-fetched
+// when the filter had to be checked by the server.
+func (l *LiveList) member(record Record) (Record, bool, error) {
+	if l.match != nil {
+		ok, err := l.match.Match(record, l.evalContext())
+		if !errors.Is(err, filter.ErrUnsupported) {
+			return record, ok, err
+		}
+	}
+	current, err := l.lookup(record.ID())
+	if IsNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return current, current != nil, nil
+}
+
+// evalContext describes the list request for the @request.* identifiers
+// of the filter.
+func (l *LiveList) evalContext() filter.EvalContext {
+	ctx := filter.EvalContext{Method: "GET", Headers: l.opts.Headers}
+	if store := l.service.client.AuthStore; store != nil && store.IsValid() {
+		ctx.Auth = store.Record()
+	}
+	if len(l.opts.Query) > 0 {
+		ctx.Query = make(map[string]string, len(l.opts.Query))
+		for k, v := range l.opts.Query {
+			ctx.Query[k] = fmt.Sprint(v)
+		}
+	}
+	return ctx
+}
+
+// lookup returns the record if it still matches the filter.
+func (l *LiveList) lookup(id string) (Record, error) {
+	where, err := filter.Raw(l.opts.Filter, nil).And(filter.Eq("id", id)).Build()
+	if err != nil {
+		return nil, err
+	}
+	return l.service.GetFirstRecord(l.ctx, where, &CrudViewOptions{
+		Expand:  l.opts.Expand,
+		Fields:  l.opts.Fields,
+		Query:   l.opts.Query,
+		Headers: l.opts.Headers,
+	})
+}
+
+func (l *LiveList) upsert(record Record) LiveDiff {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if i := l.indexOf(record.ID()); i >= 0 {
+		l.items[i] = record
+		l.sortLocked()
+		return LiveDiff{Updated: []Record{record}}
+	}
+	l.items = append(l.items, record)
+	l.sortLocked()
+	return LiveDiff{Created: []Record{record}}
+}
+
+func (l *LiveList) remove(id string) LiveDiff {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	i := l.indexOf(id)
+	if i < 0 {
+		return LiveDiff{}
+	}
+	removed := l.items[i]
+	l.items = append(l.items[:i:i], l.items[i+1:]...)
+	return LiveDiff{Deleted: []Record{removed}}
+}
+
+// replace swaps in a reloaded list and returns the difference.
+func (l *LiveList) replace(items []Record) LiveDiff {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	diff := LiveDiff{Resync: true}
+	previous := make(map[string]Record, len(l.items))
+	for _, item := range l.items {
+		previous[item.ID()] = item
+	}
+	for _, item := range items {
+		old, ok := previous[item.ID()]
+		switch {
+		case !ok:
+			diff.Created = append(diff.Created, item)
+		case !reflect.DeepEqual(old, item):
+			diff.Updated = append(diff.Updated, item)
+		}
+		delete(previous, item.ID())
+	}
+	for _, item := range l.items {
+		if _, ok := previous[item.ID()]; ok {
+			diff.Deleted = append(diff.Deleted, item)
+		}
+	}
+	l.items = items
+	l.err = nil
+	return diff
+}
+
+func (l *LiveList) fail(err error) {
+	if l.ctx.Err() != nil {
+		return
+	}
+	l.mu.Lock()
+	l.err = err
+	l.mu.Unlock()
+	l.service.client.Realtime.reportError(fmt.Errorf("live list %s: %w", l.service.collection, err))
+}
+
+func (l *LiveList) notify(diff LiveDiff) {
+	if l.opts.OnChange == nil || (diff.IsEmpty() && !diff.Resync) {
+		return
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			l.service.client.Realtime.reportError(fmt.Errorf("live list %s: panic in OnChange: %v", l.service.collection, rec))
+		}
+	}()
+	l.opts.OnChange(diff)
+}
+
+func (l *LiveList) indexOf(id string) int {
+	for i, item := range l.items {
+		if item.ID() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (l *LiveList) sortLocked() {
+	if len(l.order) == 0 {
+		return
+	}
+	sort.SliceStable(l.items, func(i, j int) bool {
+		for _, field := range l.order {
+			c := compareLiveValues(l.items[i][field.name], l.items[j][field.name])
+			if c == 0 {
+				continue
+			}
+			if field.desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+func parseLiveSort(spec string) []liveSortField {
+	var fields []liveSortField
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" || strings.HasPrefix(part, "@") {
+			continue
+		}
+		field := liveSortField{name: part}
+		switch part[0] {
+		case '-':
+			field.desc = true
+			field.name = part[1:]
+		case '+':
+			field.name = part[1:]
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// compareLiveValues orders JSON values the way the server sorts them for
+// the common field types; nil sorts first.
+func compareLiveValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	switch av := a.(type) {
+	case float64:
+		if bv, ok := b.(float64); ok {
+			switch {
+			case av < bv:
+				return -1
+			case av > bv:
+				return 1
+			}
+			return 0
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			switch {
+			case av == bv:
+				return 0
+			case !av:
+				return -1
+			}
+			return 1
+		}
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv)
+		}
+	}
+	return strings.Compare(toString(a), toString(b))
+}
@@ -0,0 +1,328 @@
+package bosbase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// liveServer fakes the realtime stream and the record list of "posts". The
+// list returns records as is, so tests keep them in the expected order.
+type liveServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	records []map[string]interface{}
+	lookups int
+	gate    chan struct{}
+	listing chan struct{}
+	drop    chan struct{}
+	events  chan string
+}
+
+func newLiveServer(t *testing.T, records ...map[string]interface{}) *liveServer {
+	s := &liveServer{records: records, listing: make(chan struct{}, 8), drop: make(chan struct{}), events: make(chan string, 16)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/realtime" && r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/api/realtime":
+			s.mu.Lock()
+			drop := s.drop
+			s.mu.Unlock()
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event:PB_CONNECT\ndata:{\"clientId\":\"c1\"}\n\n")
+			w.(http.Flusher).Flush()
+			for {
+				select {
+				case <-r.Context().Done():
+					return
+				case <-drop:
+					return
+				case data := <-s.events:
+					fmt.Fprintf(w, "event:posts/*\ndata:%s\n\n", data)
+					w.(http.Flusher).Flush()
+				}
+			}
+		case r.URL.Path == "/api/collections/posts/records":
+			s.list(w, r)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *liveServer) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	s.mu.Lock()
+	gate := s.gate
+	records := append([]map[string]interface{}(nil), s.records...)
+	lookup := query.Get("perPage") == "1" && strings.Contains(query.Get("filter"), "id = ")
+	if lookup {
+		s.lookups++
+	}
+	s.mu.Unlock()
+
+	items := []map[string]interface{}{}
+	switch {
+	case lookup:
+		for _, record := range records {
+			if strings.Contains(query.Get("filter"), fmt.Sprintf("id = '%s'", record["id"])) {
+				items = append(items, record)
+			}
+		}
+	case query.Get("page") == "1":
+		s.listing <- struct{}{}
+		if gate != nil {
+			<-gate
+		}
+		items = records
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"page": 1, "perPage": 500, "items": items})
+}
+
+func (s *liveServer) setRecords(records ...map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = records
+}
+
+func (s *liveServer) lookupCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lookups
+}
+
+// dropStream ends the current realtime stream.
+func (s *liveServer) dropStream() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	close(s.drop)
+	s.drop = make(chan struct{})
+}
+
+func (s *liveServer) send(action string, record map[string]interface{}) {
+	data, _ := json.Marshal(map[string]interface{}{"action": action, "record": record})
+	s.events <- string(data)
+}
+
+func post(id string, n float64, status string) map[string]interface{} {
+	return map[string]interface{}{"id": id, "n": n, "status": status}
+}
+
+// liveDiffs collects the OnChange diffs of a LiveList.
+type liveDiffs chan LiveDiff
+
+func (d liveDiffs) next(t *testing.T) LiveDiff {
+	t.Helper()
+	select {
+	case diff := <-d:
+		return diff
+	case <-time.After(5 * time.Second):
+		t.Fatal("no OnChange call")
+		return LiveDiff{}
+	}
+}
+
+func ids(records []Record) string {
+	out := make([]string, len(records))
+	for i, record := range records {
+		out[i] = record.ID()
+	}
+	return strings.Join(out, ",")
+}
+
+func checkDiff(t *testing.T, diff LiveDiff, created, updated, deleted string, resync bool) {
+	t.Helper()
+	if ids(diff.Created) != created || ids(diff.Updated) != updated || ids(diff.Deleted) != deleted || diff.Resync != resync {
+		t.Errorf("diff = +[%s] ~[%s] -[%s] resync=%v, want +[%s] ~[%s] -[%s] resync=%v",
+			ids(diff.Created), ids(diff.Updated), ids(diff.Deleted), diff.Resync, created, updated, deleted, resync)
+	}
+}
+
+func TestLiveBuffersEventsDuringLoad(t *testing.T) {
+	server := newLiveServer(t, post("a", 1, "open"))
+	gate := make(chan struct{})
+	server.gate = gate
+	client := New(server.URL)
+	defer client.Close()
+
+	diffs := make(liveDiffs, 16)
+	type result struct {
+		list *LiveList
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		list, err := client.Collection("posts").Live(context.Background(), LiveOptions{
+			Sort:     "-n",
+			OnChange: func(diff LiveDiff) { diffs <- diff },
+		})
+		done <- result{list, err}
+	}()
+
+	// events arriving while the list is loading are applied after it
+	<-server.listing
+	server.send("create", post("b", 2, "open"))
+	server.send("update", post("a", 3, "open"))
+	time.Sleep(100 * time.Millisecond)
+	close(gate)
+
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("Live() error = %v", res.err)
+	}
+	defer res.list.Close()
+
+	checkDiff(t, diffs.next(t), "a", "", "", false)
+	checkDiff(t, diffs.next(t), "b", "", "", false)
+	checkDiff(t, diffs.next(t), "", "a", "", false)
+	if got := ids(res.list.Items()); got != "a,b" {
+		t.Errorf("Items() = %s, want a,b", got)
+	}
+	if record, _ := res.list.Get("a"); record.GetFloat("n") != 3 {
+		t.Errorf("Get(a) = %v, want the updated record", record)
+	}
+}
+
+func TestLiveKeepsSortOrder(t *testing.T) {
+	server := newLiveServer(t, post("c", 3, "open"), post("a", 1, "open"))
+	client := New(server.URL)
+	defer client.Close()
+
+	diffs := make(liveDiffs, 16)
+	list, err := client.Collection("posts").Live(context.Background(), LiveOptions{
+		Sort:     "-n,id",
+		OnChange: func(diff LiveDiff) { diffs <- diff },
+	})
+	if err != nil {
+		t.Fatalf("Live() error = %v", err)
+	}
+	defer list.Close()
+	checkDiff(t, diffs.next(t), "c,a", "", "", false)
+
+	steps := []struct {
+		action string
+		record map[string]interface{}
+		want   string
+	}{
+		{"create", post("b", 2, "open"), "c,b,a"},
+		{"create", post("d", 2, "open"), "c,b,d,a"},
+		{"update", post("a", 5, "open"), "a,c,b,d"},
+		{"delete", post("c", 3, "open"), "a,b,d"},
+	}
+	for _, step := range steps {
+		server.send(step.action, step.record)
+		diffs.next(t)
+		if got := ids(list.Items()); got != step.want {
+			t.Errorf("after %s %s: Items() = %s, want %s", step.action, step.record["id"], got, step.want)
+		}
+	}
+}
+
+func TestLiveFilterMatchesLocally(t *testing.T) {
+	server := newLiveServer(t, post("a", 1, "open"), post("b", 2, "open"))
+	client := New(server.URL)
+	defer client.Close()
+
+	diffs := make(liveDiffs, 16)
+	list, err := client.Collection("posts").Live(context.Background(), LiveOptions{
+		Filter:   "status = 'open'",
+		Sort:     "n",
+		OnChange: func(diff LiveDiff) { diffs <- diff },
+	})
+	if err != nil {
+		t.Fatalf("Live() error = %v", err)
+	}
+	defer list.Close()
+	checkDiff(t, diffs.next(t), "a,b", "", "", false)
+
+	server.send("create", post("x", 9, "closed")) // never matches, no diff
+	server.send("update", post("a", 1, "closed"))
+	checkDiff(t, diffs.next(t), "", "", "a", false)
+	server.send("create", post("c", 3, "open"))
+	checkDiff(t, diffs.next(t), "c", "", "", false)
+	server.send("update", post("b", 4, "open"))
+	checkDiff(t, diffs.next(t), "", "b", "", false)
+	server.send("delete", post("c", 3, "open"))
+	checkDiff(t, diffs.next(t), "", "", "c", false)
+
+	if got := ids(list.Items()); got != "b" {
+		t.Errorf("Items() = %s, want b", got)
+	}
+	if got := server.lookupCount(); got != 0 {
+		t.Errorf("%d record lookups for a filter that can be matched locally", got)
+	}
+}
+
+func TestLiveFilterFallsBackToLookups(t *testing.T) {
+	server := newLiveServer(t, post("a", 1, "open"))
+	client := New(server.URL)
+	defer client.Close()
+
+	diffs := make(liveDiffs, 16)
+	list, err := client.Collection("posts").Live(context.Background(), LiveOptions{
+		Filter:   "@collection.tags.name ?= status",
+		OnChange: func(diff LiveDiff) { diffs <- diff },
+	})
+	if err != nil {
+		t.Fatalf("Live() error = %v", err)
+	}
+	defer list.Close()
+	diffs.next(t)
+
+	// the server decides: "b" is not in its list, so it doesn't match
+	server.send("create", post("b", 2, "open"))
+	server.setRecords(post("a", 1, "open"), post("c", 3, "open"))
+	server.send("create", post("c", 3, "open"))
+	checkDiff(t, diffs.next(t), "c", "", "", false)
+	if got := server.lookupCount(); got != 2 {
+		t.Errorf("%d record lookups, want one per event", got)
+	}
+}
+
+func TestLiveResyncsOnReconnect(t *testing.T) {
+	server := newLiveServer(t, post("a", 1, "open"), post("b", 2, "open"))
+	client := New(server.URL)
+	client.Realtime.ReconnectPolicy = ReconnectPolicy{InitialDelay: 10 * time.Millisecond}
+	defer client.Close()
+
+	diffs := make(liveDiffs, 16)
+	list, err := client.Collection("posts").Live(context.Background(), LiveOptions{
+		Sort:     "n",
+		OnChange: func(diff LiveDiff) { diffs <- diff },
+	})
+	if err != nil {
+		t.Fatalf("Live() error = %v", err)
+	}
+	defer list.Close()
+	checkDiff(t, diffs.next(t), "a,b", "", "", false)
+
+	// changes missed while disconnected are picked up by the reload
+	server.setRecords(post("b", 5, "open"), post("c", 6, "open"))
+	server.dropStream()
+	checkDiff(t, diffs.next(t), "c", "b", "a", true)
+	if got := ids(list.Items()); got != "b,c" {
+		t.Errorf("Items() = %s, want b,c", got)
+	}
+	if !reflect.DeepEqual(map[string]interface{}(list.Items()[0]), post("b", 5, "open")) {
+		t.Errorf("Items()[0] = %v", list.Items()[0])
+	}
+
+	list.Close()
+	select {
+	case <-list.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Done() not closed after Close()")
+	}
+}
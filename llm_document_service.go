@@ -1,6 +1,9 @@
 package bosbase
 
-import "net/http"
+import (
+    "context"
+    "net/http"
+)
 
 type LLMDocumentService struct {
     BaseService
@@ -16,7 +19,11 @@ func (s *LLMDocumentService) collectionPath(collection string) string {
 }
 
 func (s *LLMDocumentService) ListCollections(query map[string]interface{}, headers map[string]string) ([]map[string]interface{}, error) {
-    data, err := s.client.Send(s.basePath+"/collections", &RequestOptions{Query: query, Headers: headers})
+    return s.ListCollectionsCtx(context.Background(), query, headers)
+}
+
+func (s *LLMDocumentService) ListCollectionsCtx(ctx context.Context, query map[string]interface{}, headers map[string]string) ([]map[string]interface{}, error) {
+    data, err := s.client.SendContext(ctx, s.basePath+"/collections", &RequestOptions{Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -32,17 +39,29 @@ func (s *LLMDocumentService) ListCollections(query map[string]interface{}, heade
 }
 
 func (s *LLMDocumentService) CreateCollection(name string, metadata map[string]string, query map[string]interface{}, headers map[string]string) error {
-    _, err := s.client.Send(s.basePath+"/collections/"+encodePathSegment(name), &RequestOptions{Method: http.MethodPost, Body: map[string]interface{}{"metadata": metadata}, Query: query, Headers: headers})
+    return s.CreateCollectionCtx(context.Background(), name, metadata, query, headers)
+}
+
+func (s *LLMDocumentService) CreateCollectionCtx(ctx context.Context, name string, metadata map[string]string, query map[string]interface{}, headers map[string]string) error {
+    _, err := s.client.SendContext(ctx, s.basePath+"/collections/"+encodePathSegment(name), &RequestOptions{Method: http.MethodPost, Body: map[string]interface{}{"metadata": metadata}, Query: query, Headers: headers})
     return err
 }
 
 func (s *LLMDocumentService) DeleteCollection(name string, query map[string]interface{}, headers map[string]string) error {
-    _, err := s.client.Send(s.basePath+"/collections/"+encodePathSegment(name), &RequestOptions{Method: http.MethodDelete, Query: query, Headers: headers})
+    return s.DeleteCollectionCtx(context.Background(), name, query, headers)
+}
+
+func (s *LLMDocumentService) DeleteCollectionCtx(ctx context.Context, name string, query map[string]interface{}, headers map[string]string) error {
+    _, err := s.client.SendContext(ctx, s.basePath+"/collections/"+encodePathSegment(name), &RequestOptions{Method: http.MethodDelete, Query: query, Headers: headers})
     return err
 }
 
 func (s *LLMDocumentService) Insert(collection string, doc LLMDocument, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
-    data, err := s.client.Send(s.collectionPath(collection), &RequestOptions{Method: http.MethodPost, Body: doc.ToMap(), Query: query, Headers: headers})
+    return s.InsertCtx(context.Background(), collection, doc, query, headers)
+}
+
+func (s *LLMDocumentService) InsertCtx(ctx context.Context, collection string, doc LLMDocument, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    data, err := s.client.SendContext(ctx, s.collectionPath(collection), &RequestOptions{Method: http.MethodPost, Body: doc.ToMap(), Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -53,7 +72,11 @@ func (s *LLMDocumentService) Insert(collection string, doc LLMDocument, query ma
 }
 
 func (s *LLMDocumentService) Get(collection, documentID string, query map[string]interface{}, headers map[string]string) (LLMDocument, error) {
-    data, err := s.client.Send(s.collectionPath(collection)+"/"+encodePathSegment(documentID), &RequestOptions{Query: query, Headers: headers})
+    return s.GetCtx(context.Background(), collection, documentID, query, headers)
+}
+
+func (s *LLMDocumentService) GetCtx(ctx context.Context, collection, documentID string, query map[string]interface{}, headers map[string]string) (LLMDocument, error) {
+    data, err := s.client.SendContext(ctx, s.collectionPath(collection)+"/"+encodePathSegment(documentID), &RequestOptions{Query: query, Headers: headers})
     if err != nil {
         return LLMDocument{}, err
     }
@@ -64,7 +87,11 @@ func (s *LLMDocumentService) Get(collection, documentID string, query map[string
 }
 
 func (s *LLMDocumentService) Update(collection, documentID string, doc LLMDocumentUpdate, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
-    data, err := s.client.Send(s.collectionPath(collection)+"/"+encodePathSegment(documentID), &RequestOptions{Method: http.MethodPatch, Body: doc.ToMap(), Query: query, Headers: headers})
+    return s.UpdateCtx(context.Background(), collection, documentID, doc, query, headers)
+}
+
+func (s *LLMDocumentService) UpdateCtx(ctx context.Context, collection, documentID string, doc LLMDocumentUpdate, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    data, err := s.client.SendContext(ctx, s.collectionPath(collection)+"/"+encodePathSegment(documentID), &RequestOptions{Method: http.MethodPatch, Body: doc.ToMap(), Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -75,11 +102,19 @@ func (s *LLMDocumentService) Update(collection, documentID string, doc LLMDocume
 }
 
 func (s *LLMDocumentService) Delete(collection, documentID string, query map[string]interface{}, headers map[string]string) error {
-    _, err := s.client.Send(s.collectionPath(collection)+"/"+encodePathSegment(documentID), &RequestOptions{Method: http.MethodDelete, Query: query, Headers: headers})
+    return s.DeleteCtx(context.Background(), collection, documentID, query, headers)
+}
+
+func (s *LLMDocumentService) DeleteCtx(ctx context.Context, collection, documentID string, query map[string]interface{}, headers map[string]string) error {
+    _, err := s.client.SendContext(ctx, s.collectionPath(collection)+"/"+encodePathSegment(documentID), &RequestOptions{Method: http.MethodDelete, Query: query, Headers: headers})
     return err
 }
 
 func (s *LLMDocumentService) List(collection string, page *int, perPage *int, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.ListCtx(context.Background(), collection, page, perPage, query, headers)
+}
+
+func (s *LLMDocumentService) ListCtx(ctx context.Context, collection string, page *int, perPage *int, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     params := cloneQuery(query)
     if page != nil {
         params["page"] = *page
@@ -87,7 +122,7 @@ func (s *LLMDocumentService) List(collection string, page *int, perPage *int, qu
     if perPage != nil {
         params["perPage"] = *perPage
     }
-    data, err := s.client.Send(s.collectionPath(collection), &RequestOptions{Query: params, Headers: headers})
+    data, err := s.client.SendContext(ctx, s.collectionPath(collection), &RequestOptions{Query: params, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -98,8 +133,12 @@ func (s *LLMDocumentService) List(collection string, page *int, perPage *int, qu
 }
 
 func (s *LLMDocumentService) Query(collection string, options LLMQueryOptions, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.QueryCtx(context.Background(), collection, options, query, headers)
+}
+
+func (s *LLMDocumentService) QueryCtx(ctx context.Context, collection string, options LLMQueryOptions, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     path := s.collectionPath(collection) + "/documents/query"
-    data, err := s.client.Send(path, &RequestOptions{Method: http.MethodPost, Body: options.ToMap(), Query: query, Headers: headers})
+    data, err := s.client.SendContext(ctx, path, &RequestOptions{Method: http.MethodPost, Body: options.ToMap(), Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
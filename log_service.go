@@ -1,7 +1,9 @@
 package bosbase
 
 import (
+    "context"
     "fmt"
+    "net/http"
     "strings"
 )
 
@@ -14,6 +16,10 @@ func NewLogService(client *BosBase) *LogService {
 }
 
 func (s *LogService) GetList(page, perPage int, filter string, sort string, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.GetListCtx(context.Background(), page, perPage, filter, sort, query, headers)
+}
+
+func (s *LogService) GetListCtx(ctx context.Context, page, perPage int, filter string, sort string, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     if page <= 0 {
         page = 1
     }
@@ -29,7 +35,7 @@ func (s *LogService) GetList(page, perPage int, filter string, sort string, quer
     if sort != "" {
         params["sort"] = sort
     }
-    data, err := s.client.Send("/api/logs", &RequestOptions{Query: params, Headers: headers})
+    data, err := s.client.SendContext(ctx, "/api/logs", &RequestOptions{Query: params, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -40,18 +46,14 @@ func (s *LogService) GetList(page, perPage int, filter string, sort string, quer
 }
 
 func (s *LogService) GetOne(logID string, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.GetOneCtx(context.Background(), logID, query, headers)
+}
+
+func (s *LogService) GetOneCtx(ctx context.Context, logID string, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     if strings.TrimSpace(logID) == "" {
-        return nil, &ClientResponseError{
-            URL:    s.client.BuildURL("/api/logs/", nil),
-            Status: 404,
-            Response: map[string]interface{}{
-                "code":    404,
-                "message": "Missing required log id.",
-                "data":    map[string]interface{}{},
-            },
-        }
+        return nil, newClientError(s.client.BuildURL("/api/logs/", nil), http.StatusNotFound, "Missing required log id.")
     }
-    data, err := s.client.Send(fmt.Sprintf("/api/logs/%s", logID), &RequestOptions{Query: query, Headers: headers})
+    data, err := s.client.SendContext(ctx, fmt.Sprintf("/api/logs/%s", logID), &RequestOptions{Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -62,7 +64,11 @@ func (s *LogService) GetOne(logID string, query map[string]interface{}, headers
 }
 
 func (s *LogService) GetStats(query map[string]interface{}, headers map[string]string) ([]map[string]interface{}, error) {
-    data, err := s.client.Send("/api/logs/stats", &RequestOptions{Query: query, Headers: headers})
+    return s.GetStatsCtx(context.Background(), query, headers)
+}
+
+func (s *LogService) GetStatsCtx(ctx context.Context, query map[string]interface{}, headers map[string]string) ([]map[string]interface{}, error) {
+    data, err := s.client.SendContext(ctx, "/api/logs/stats", &RequestOptions{Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
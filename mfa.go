@@ -0,0 +1,106 @@
+package bosbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// MFAChallenge is the error returned by the auth methods of a collection
+// with MFA enabled once the first auth method succeeded. It carries the
+// mfaId and completes the flow with a second, different auth method, saving
+// the final token to the AuthStore.
+//
+//	_, err := client.Collection("users").AuthWithPassword(email, password, "", "", nil, nil, nil)
+//	var mfa *bosbase.MFAChallenge
+//	if errors.As(err, &mfa) {
+//		otpID, err := mfa.RequestOTP(ctx, email)
+//		...
+//		_, err = mfa.CompleteWithOTP(ctx, otpID, codeFromUser)
+//	}
+//
+// It unwraps to the original *ClientResponseError, so IsMFARequired and
+// errors.Is(err, ErrMFARequired) keep working.
+type MFAChallenge struct {
+	// MFAID identifies the pending MFA session.
+	MFAID string
+	// Method is the auth method that already succeeded
+	// ("password", "otp", "oauth2" or "token").
+	Method string
+
+	err     *ClientResponseError
+	service *RecordService
+}
+
+// mfaChallenge converts an MFA-required error into an *MFAChallenge.
+func (s *RecordService) mfaChallenge(err error, method string) error {
+	mfaID, ok := IsMFARequired(err)
+	if !ok {
+		return err
+	}
+	var cre *ClientResponseError
+	errors.As(err, &cre)
+	return &MFAChallenge{MFAID: mfaID, Method: method, err: cre, service: s}
+}
+
+func (c *MFAChallenge) Error() string {
+	return fmt.Sprintf("bosbase: multi-factor authentication required (mfaId=%s, completed=%s)", c.MFAID, c.Method)
+}
+
+// Unwrap returns the original 401 response error.
+func (c *MFAChallenge) Unwrap() error {
+	if c.err == nil {
+		return nil
+	}
+	return c.err
+}
+
+// Methods returns the auth methods enabled for the collection that can still
+// complete the challenge, i.e. all enabled methods except Method.
+func (c *MFAChallenge) Methods(ctx context.Context) ([]string, error) {
+	methods, err := c.service.ListAuthMethodsCtx(ctx, "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, name := range []string{"password", "otp", "oauth2"} {
+		if name == c.Method {
+			continue
+		}
+		if cfg, ok := methods[name].(map[string]interface{}); ok {
+			if enabled, _ := cfg["enabled"].(bool); enabled {
+				result = append(result, name)
+			}
+		}
+	}
+	return result, nil
+}
+
+// RequestOTP sends a one-time password to email and returns its otpId.
+func (c *MFAChallenge) RequestOTP(ctx context.Context, email string) (string, error) {
+	data, err := c.service.RequestOTPCtx(ctx, email, nil, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	otpID, _ := data["otpId"].(string)
+	return otpID, nil
+}
+
+// CompleteWithOTP finishes the MFA flow with a one-time password.
+func (c *MFAChallenge) CompleteWithOTP(ctx context.Context, otpID, code string) (map[string]interface{}, error) {
+	return c.service.AuthWithOTPCtx(ctx, otpID, code, "", "", c.body(), nil, nil)
+}
+
+// CompleteWithPassword finishes the MFA flow with identity and password.
+func (c *MFAChallenge) CompleteWithPassword(ctx context.Context, identity, password string) (map[string]interface{}, error) {
+	return c.service.AuthWithPasswordCtx(ctx, identity, password, "", "", c.body(), nil, nil)
+}
+
+// CompleteWithOAuth2Code finishes the MFA flow with an OAuth2 authorization code.
+func (c *MFAChallenge) CompleteWithOAuth2Code(ctx context.Context, provider, code, codeVerifier, redirectURL string) (map[string]interface{}, error) {
+	return c.service.AuthWithOAuth2CodeCtx(ctx, provider, code, codeVerifier, redirectURL, nil, c.body(), nil, nil, "", "")
+}
+
+func (c *MFAChallenge) body() map[string]interface{} {
+	return map[string]interface{}{"mfaId": c.MFAID}
+}
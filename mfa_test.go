@@ -0,0 +1,57 @@
+package bosbase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthMethodsReturnMFAChallenge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"status":401,"message":"Missing MFA.","data":{"mfaId":"mfa1"}}`))
+	}))
+	defer server.Close()
+
+	users := New(server.URL).Collection("users")
+	ctx := context.Background()
+	cases := []struct {
+		method string
+		call   func() error
+	}{
+		{"password", func() error {
+			_, err := users.AuthWithPasswordCtx(ctx, "a@b.c", "secret", "", "", nil, nil, nil)
+			return err
+		}},
+		{"otp", func() error {
+			_, err := users.AuthWithOTPCtx(ctx, "otp1", "123456", "", "", nil, nil, nil)
+			return err
+		}},
+		{"oauth2", func() error {
+			_, err := users.AuthWithOAuth2CodeCtx(ctx, "google", "code", "verifier", "http://localhost", nil, nil, nil, nil, "", "")
+			return err
+		}},
+		{"token", func() error {
+			_, err := users.AuthWithTokenCtx(ctx, "custom", "", "", nil, nil, nil)
+			return err
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.method, func(t *testing.T) {
+			err := tc.call()
+			var mfa *MFAChallenge
+			if !errors.As(err, &mfa) {
+				t.Fatalf("error = %v, want *MFAChallenge", err)
+			}
+			if mfa.MFAID != "mfa1" || mfa.Method != tc.method {
+				t.Errorf("challenge = %+v", mfa)
+			}
+			if !errors.Is(err, ErrMFARequired) {
+				t.Error("the challenge should match ErrMFARequired")
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package bosbase
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Request describes an outgoing API call as seen by middlewares.
+//
+// URL is built from Path and Query before the chain runs. A middleware that
+// changes Query should either rebuild URL (for example with BosBase.BuildURL)
+// or clear it so the transport rebuilds it from Path and Query.
+type Request struct {
+	Method     string
+	URL        string
+	Path       string
+	Headers    map[string]string
+	Query      map[string]interface{}
+	Body       interface{}
+	Files      map[string]FileParam
+	Timeout    time.Duration
+	Idempotent bool
+	OnProgress ProgressFunc
+	Stream     bool
+}
+
+// Response is the outcome of an API call as seen by middlewares.
+//
+// HTTP is the raw response whose body has already been consumed and decoded
+// into Data (for Stream requests the body is left open and Data is HTTP
+// itself). It is nil when the request never reached the server or when a
+// middleware short-circuited the chain.
+type Response struct {
+	HTTP *http.Response
+	Data interface{}
+}
+
+// Handler executes a Request.
+type Handler func(ctx context.Context, req *Request) (*Response, error)
+
+// Middleware wraps a Handler. A middleware may modify the request before
+// calling next, inspect or replace the response afterwards, or return
+// without calling next to short-circuit the call.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware appends middlewares to the client chain during construction.
+func WithMiddleware(mw ...Middleware) ClientOption {
+	return func(c *BosBase) { c.middlewares = append(c.middlewares, mw...) }
+}
+
+// Use appends middlewares to the client chain. Middlewares run in the order
+// they were added, after the BeforeSend hook and before the AfterSend hook.
+func (c *BosBase) Use(mw ...Middleware) {
+	c.mu.Lock()
+	c.middlewares = append(c.middlewares, mw...)
+	c.mu.Unlock()
+}
+
+// handler assembles the middleware chain for a single call.
+func (c *BosBase) handler() Handler {
+	c.mu.Lock()
+	mws := append([]Middleware{}, c.middlewares...)
+	c.mu.Unlock()
+
+	h := c.autoRefreshMiddleware()(c.transport)
+	h = c.afterSendMiddleware()(h)
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return c.beforeSendMiddleware()(h)
+}
+
+// beforeSendMiddleware adapts the legacy BeforeSend hook.
+func (c *BosBase) beforeSendMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			if c.BeforeSend == nil {
+				return next(ctx, req)
+			}
+			hookOpts := &HookOptions{
+				Method:  req.Method,
+				Headers: cloneHeaders(req.Headers),
+				Body:    req.Body,
+				Query:   cloneQuery(req.Query),
+				Files:   cloneFiles(req.Files),
+				Timeout: req.Timeout,
+			}
+			override, err := c.BeforeSend(req.URL, hookOpts)
+			if err != nil {
+				return nil, err
+			}
+			req.Method = strings.ToUpper(hookOpts.Method)
+			req.Headers = cloneHeaders(hookOpts.Headers)
+			req.Query = cloneQuery(hookOpts.Query)
+			req.Files = cloneFiles(hookOpts.Files)
+			req.Body = hookOpts.Body
+			req.URL = c.BuildURL(req.Path, req.Query)
+			if override != nil {
+				if override.URL != "" {
+					req.URL = override.URL
+				}
+				if override.Options != nil {
+					if override.Options.Method != "" {
+						req.Method = strings.ToUpper(override.Options.Method)
+					}
+					if override.Options.Headers != nil {
+						req.Headers = cloneHeaders(override.Options.Headers)
+					}
+					if override.Options.Query != nil {
+						req.Query = cloneQuery(override.Options.Query)
+						req.URL = c.BuildURL(req.Path, req.Query)
+					}
+					if override.Options.Body != nil {
+						req.Body = override.Options.Body
+					}
+					if override.Options.Files != nil {
+						req.Files = cloneFiles(override.Options.Files)
+					}
+					if override.Options.Timeout > 0 {
+						req.Timeout = override.Options.Timeout
+					}
+				}
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// afterSendMiddleware adapts the legacy AfterSend hook.
+func (c *BosBase) afterSendMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			resp, err := next(ctx, req)
+			if err != nil || c.AfterSend == nil || resp == nil {
+				return resp, err
+			}
+			data, err := c.AfterSend(resp.HTTP, resp.Data)
+			if err != nil {
+				return nil, err
+			}
+			resp.Data = data
+			return resp, nil
+		}
+	}
+}
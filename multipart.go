@@ -0,0 +1,159 @@
+package bosbase
+
+import (
+	"io"
+	"mime/multipart"
+	"sort"
+)
+
+// streamMultipart returns a reader producing the multipart body for the given
+// JSON payload and files. The body is generated on the fly through an io.Pipe,
+// so file contents are never buffered in memory. The returned length is -1
+// when the size of any file is unknown.
+//
+// The returned wait func blocks until the writer goroutine stopped using the
+// file readers. Callers must close the body first and wait before the files
+// are read again, e.g. rewound for a retry.
+func streamMultipart(jsonPayload []byte, files map[string]FileParam) (io.ReadCloser, string, int64, func()) {
+	fields := make([]string, 0, len(files))
+	for field := range files {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	length := multipartLength(writer.Boundary(), jsonPayload, fields, files)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		err := writeMultipart(writer, jsonPayload, fields, files)
+		if err == nil {
+			err = writer.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return pr, writer.FormDataContentType(), length, func() { <-done }
+}
+
+func writeMultipart(writer *multipart.Writer, jsonPayload []byte, fields []string, files map[string]FileParam) error {
+	if err := writer.WriteField("@jsonPayload", string(jsonPayload)); err != nil {
+		return err
+	}
+	for _, field := range fields {
+		file := files[field]
+		part, err := writer.CreatePart(textprotoMIMEHeader(field, file))
+		if err != nil {
+			return err
+		}
+		if file.Reader == nil {
+			continue
+		}
+		var dst io.Writer = part
+		if file.OnProgress != nil {
+			dst = &progressWriter{w: part, total: fileSize(file), fn: file.OnProgress}
+		}
+		if _, err := io.Copy(dst, file.Reader); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// multipartLength computes the exact size of the multipart body without
+// reading any file contents.
+func multipartLength(boundary string, jsonPayload []byte, fields []string, files map[string]FileParam) int64 {
+	counter := &countingWriter{}
+	writer := multipart.NewWriter(counter)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return -1
+	}
+	if err := writer.WriteField("@jsonPayload", string(jsonPayload)); err != nil {
+		return -1
+	}
+	for _, field := range fields {
+		file := files[field]
+		if _, err := writer.CreatePart(textprotoMIMEHeader(field, file)); err != nil {
+			return -1
+		}
+		size := fileSize(file)
+		if size < 0 {
+			return -1
+		}
+		counter.n += size
+	}
+	if err := writer.Close(); err != nil {
+		return -1
+	}
+	return counter.n
+}
+
+// fileSize returns the number of bytes remaining in the file reader or -1.
+func fileSize(file FileParam) int64 {
+	if file.Size > 0 {
+		return file.Size
+	}
+	switch r := file.Reader.(type) {
+	case nil:
+		return 0
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case io.Seeker:
+		cur, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		end, err := r.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1
+		}
+		if _, err := r.Seek(cur, io.SeekStart); err != nil {
+			return -1
+		}
+		return end - cur
+	}
+	return -1
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+type progressReader struct {
+	r     io.Reader
+	sent  int64
+	total int64
+	fn    ProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.sent += int64(n)
+		r.fn(r.sent, r.total)
+	}
+	return n, err
+}
+
+type progressWriter struct {
+	w     io.Writer
+	sent  int64
+	total int64
+	fn    ProgressFunc
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if n > 0 {
+		w.sent += int64(n)
+		w.fn(w.sent, w.total)
+	}
+	return n, err
+}
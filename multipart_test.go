@@ -0,0 +1,92 @@
+package bosbase
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStreamMultipartLength(t *testing.T) {
+	files := map[string]FileParam{
+		"avatar": {Filename: "a.txt", Reader: strings.NewReader("hello")},
+		"doc":    {Filename: "b.bin", Reader: bytes.NewReader(make([]byte, 1024))},
+	}
+	body, contentType, length, wait := streamMultipart([]byte(`{"title":"x"}`), files)
+	raw, err := io.ReadAll(body)
+	wait()
+	if err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+	if !strings.HasPrefix(contentType, "multipart/form-data; boundary=") {
+		t.Errorf("content type = %q", contentType)
+	}
+	if length != int64(len(raw)) {
+		t.Errorf("length = %d, body has %d bytes", length, len(raw))
+	}
+}
+
+// slowSeeker fails the test when Seek is called while a Read is running.
+type slowSeeker struct {
+	t       *testing.T
+	r       *bytes.Reader
+	reading int32
+}
+
+func (s *slowSeeker) Read(p []byte) (int, error) {
+	atomic.StoreInt32(&s.reading, 1)
+	defer atomic.StoreInt32(&s.reading, 0)
+	time.Sleep(time.Millisecond)
+	if len(p) > 4096 {
+		p = p[:4096]
+	}
+	return s.r.Read(p)
+}
+
+func (s *slowSeeker) Seek(offset int64, whence int) (int64, error) {
+	if atomic.LoadInt32(&s.reading) == 1 {
+		s.t.Error("file rewound while the previous attempt was still reading it")
+	}
+	return s.r.Seek(offset, whence)
+}
+
+func TestRetriedUploadSendsFullBody(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 1<<12)
+	var calls int32
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// reject before the upload finished, so the next attempt rewinds
+			// the file while the previous body may still be written
+			w.Header().Set("Connection", "close")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		file, _, err := r.FormFile("doc")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		received, _ = io.ReadAll(file)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	client := New(server.URL, WithRetryPolicy(RetryPolicy{InitialBackoff: time.Millisecond, RetryPOST: true}))
+	_, err := client.SendContext(context.Background(), "/api/upload", &RequestOptions{
+		Method: http.MethodPost,
+		Files:  map[string]FileParam{"doc": {Filename: "doc.bin", Reader: &slowSeeker{t: t, r: bytes.NewReader(content)}}},
+	})
+	if err != nil {
+		t.Fatalf("SendContext() error = %v", err)
+	}
+	if !bytes.Equal(received, content) {
+		t.Errorf("server received %d bytes, want %d identical bytes", len(received), len(content))
+	}
+}
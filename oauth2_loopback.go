@@ -0,0 +1,235 @@
+package bosbase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// OAuth2LoopbackOptions configures AuthWithOAuth2Loopback.
+type OAuth2LoopbackOptions struct {
+	// Provider is the OAuth2 provider name, e.g. "google".
+	Provider string
+	Scopes   []string
+	// Addr is the loopback address to listen on. It defaults to
+	// "127.0.0.1:0" (a random free port); use a fixed port when the provider
+	// only accepts pre-registered redirect URLs.
+	Addr string
+	// CallbackPath is the path of the redirect URL (defaults to "/callback").
+	CallbackPath string
+	// OpenURL presents the provider auth URL to the user. It defaults to
+	// printing the URL to stderr; OpenBrowser opens it in the default browser.
+	OpenURL func(authURL string) error
+	// Timeout bounds the whole flow (defaults to 3 minutes).
+	Timeout time.Duration
+
+	CreateData map[string]interface{}
+	Body       map[string]interface{}
+	Query      map[string]interface{}
+	Headers    map[string]string
+	Expand     string
+	Fields     string
+}
+
+// AuthWithOAuth2Loopback authenticates with an OAuth2 provider without the
+// realtime connection used by AuthWithOAuth2, for CLI and desktop apps.
+//
+// It starts a temporary HTTP listener on 127.0.0.1 and presents the provider
+// auth URL (with a fresh state and the PKCE challenge issued by the server)
+// redirecting back to it. The code of the first callback carrying that state
+// is exchanged with AuthWithOAuth2Code and later callbacks are rejected. The
+// listener is shut down on completion, cancellation or timeout. The loopback
+// redirect URL must be allowed in the provider's app settings.
+func (s *RecordService) AuthWithOAuth2Loopback(ctx context.Context, opts OAuth2LoopbackOptions) (map[string]interface{}, error) {
+	provider, err := s.findOAuth2Provider(ctx, opts.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := opts.Addr
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+	if host, _, err := net.SplitHostPort(addr); err != nil {
+		return nil, err
+	} else if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("oauth2 loopback address %q is not a loopback address", addr)
+	}
+	callbackPath := opts.CallbackPath
+	if callbackPath == "" {
+		callbackPath = "/callback"
+	}
+	if !strings.HasPrefix(callbackPath, "/") {
+		callbackPath = "/" + callbackPath
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 3 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	redirectURL := "http://" + listener.Addr().String() + callbackPath
+
+	state, err := randomState()
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	codeVerifier, _ := provider["codeVerifier"].(string)
+
+	type result struct {
+		auth map[string]interface{}
+		err  error
+	}
+	done := make(chan result, 1)
+	var handled int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(callbackPath, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("state") != state {
+			http.Error(w, "Invalid OAuth2 state.", http.StatusBadRequest)
+			return
+		}
+		// the code is exchanged at most once, for the first valid callback
+		if !atomic.CompareAndSwapInt32(&handled, 0, 1) {
+			http.Error(w, "The OAuth2 flow has already completed.", http.StatusGone)
+			return
+		}
+		var res result
+		if msg := q.Get("error"); msg != "" {
+			if desc := q.Get("error_description"); desc != "" {
+				msg += ": " + desc
+			}
+			res.err = newClientError(redirectURL, 0, msg)
+		} else if code := q.Get("code"); code == "" {
+			res.err = newClientError(redirectURL, 0, "OAuth2 redirect missing code")
+		} else {
+			res.auth, res.err = s.AuthWithOAuth2CodeCtx(ctx, opts.Provider, code, codeVerifier, redirectURL, opts.CreateData, opts.Body, opts.Query, opts.Headers, opts.Expand, opts.Fields)
+		}
+		writeLoopbackPage(w, res.err)
+		done <- res
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener)
+	defer func() {
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancelShutdown()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	authURL, err := url.Parse(fmt.Sprint(provider["authURL"]) + redirectURL)
+	if err != nil {
+		return nil, err
+	}
+	q := authURL.Query()
+	q.Set("state", state)
+	if len(opts.Scopes) > 0 {
+		q.Set("scope", strings.Join(opts.Scopes, " "))
+	}
+	authURL.RawQuery = q.Encode()
+
+	openURL := opts.OpenURL
+	if openURL == nil {
+		openURL = printAuthURL
+	}
+	if err := openURL(authURL.String()); err != nil {
+		return nil, err
+	}
+
+	select {
+	case res := <-done:
+		return res.auth, res.err
+	case <-ctx.Done():
+		err := newAbortError(redirectURL, ctx.Err())
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err.Response = map[string]interface{}{"message": "OAuth2 flow timed out"}
+		}
+		return nil, err
+	}
+}
+
+// OpenBrowser opens rawURL in the default browser. It can be used as
+// OAuth2LoopbackOptions.OpenURL.
+func OpenBrowser(rawURL string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", rawURL)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", rawURL)
+	default:
+		cmd = exec.Command("xdg-open", rawURL)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+func printAuthURL(authURL string) error {
+	_, err := fmt.Fprintf(os.Stderr, "Open the following URL in your browser to continue:\n\n  %s\n\n", authURL)
+	return err
+}
+
+// findOAuth2Provider returns the auth-methods entry of the named provider.
+func (s *RecordService) findOAuth2Provider(ctx context.Context, providerName string) (map[string]interface{}, error) {
+	methods, err := s.ListAuthMethodsCtx(ctx, "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if oauth, ok := methods["oauth2"].(map[string]interface{}); ok {
+		providers, _ := oauth["providers"].([]interface{})
+		for _, item := range providers {
+			if m, ok := item.(map[string]interface{}); ok {
+				if name, _ := m["name"].(string); name == providerName {
+					return m, nil
+				}
+			}
+		}
+	}
+	return nil, newClientError("", 0, fmt.Sprintf("missing provider %s", providerName))
+}
+
+func randomState() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func writeLoopbackPage(w http.ResponseWriter, err error) {
+	title, message := "Authentication complete", "You can close this window and return to the application."
+	status := http.StatusOK
+	if err != nil {
+		title, message = "Authentication failed", err.Error()
+		var cre *ClientResponseError
+		if errors.As(err, &cre) && cre.Message() != "" {
+			message = cre.Message()
+		}
+		status = http.StatusBadRequest
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<!DOCTYPE html><html><head><title>%s</title></head><body><h1>%s</h1><p>%s</p></body></html>",
+		html.EscapeString(title), html.EscapeString(title), html.EscapeString(message))
+}
@@ -0,0 +1,176 @@
+package bosbase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// oauth2Server fakes the auth-methods and auth-with-oauth2 endpoints and
+// records the code exchanges.
+type oauth2Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	exchanges []map[string]interface{}
+}
+
+func newOAuth2Server(t *testing.T) *oauth2Server {
+	s := &oauth2Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/auth-methods"):
+			w.Write([]byte(`{"oauth2":{"enabled":true,"providers":[{"name":"test","codeVerifier":"verifier","authURL":"https://provider.test/auth?client_id=app&redirect_uri="}]}}`))
+		case strings.HasSuffix(r.URL.Path, "/auth-with-oauth2"):
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			s.mu.Lock()
+			s.exchanges = append(s.exchanges, body)
+			s.mu.Unlock()
+			w.Write([]byte(`{"token":"oauth-token","record":{"id":"u1","collectionName":"users"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *oauth2Server) codeExchanges() []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]interface{}(nil), s.exchanges...)
+}
+
+var loopbackClient = &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 5 * time.Second}
+
+// callback follows the provider redirect back to the loopback listener.
+func callback(t *testing.T, authURL string, params url.Values) int {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	redirect := u.Query().Get("redirect_uri")
+	if params.Get("state") == "" {
+		params.Set("state", u.Query().Get("state"))
+	}
+	resp, err := loopbackClient.Get(redirect + "?" + params.Encode())
+	if err != nil {
+		t.Errorf("callback error = %v", err)
+		return 0
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestAuthWithOAuth2Loopback(t *testing.T) {
+	server := newOAuth2Server(t)
+	client := New(server.URL)
+
+	var redirectURL string
+	auth, err := client.Collection("users").AuthWithOAuth2Loopback(context.Background(), OAuth2LoopbackOptions{
+		Provider: "test",
+		Scopes:   []string{"email", "profile"},
+		OpenURL: func(authURL string) error {
+			u, _ := url.Parse(authURL)
+			redirectURL = u.Query().Get("redirect_uri")
+			if got := u.Query().Get("scope"); got != "email profile" {
+				t.Errorf("scope = %q", got)
+			}
+			if got := callback(t, authURL, url.Values{"code": {"the-code"}}); got != http.StatusOK {
+				t.Errorf("callback status = %d", got)
+			}
+			// a replayed callback must not be exchanged again
+			if got := callback(t, authURL, url.Values{"code": {"other-code"}}); got != http.StatusGone {
+				t.Errorf("second callback status = %d, want %d", got, http.StatusGone)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("AuthWithOAuth2Loopback() error = %v", err)
+	}
+	if auth["token"] != "oauth-token" || client.AuthStore.Token() != "oauth-token" {
+		t.Errorf("auth = %v", auth)
+	}
+
+	exchanges := server.codeExchanges()
+	if len(exchanges) != 1 {
+		t.Fatalf("%d code exchanges, want 1", len(exchanges))
+	}
+	want := map[string]interface{}{"provider": "test", "code": "the-code", "codeVerifier": "verifier", "redirectURL": redirectURL}
+	for key, value := range want {
+		if exchanges[0][key] != value {
+			t.Errorf("exchange %s = %v, want %v", key, exchanges[0][key], value)
+		}
+	}
+	if !strings.HasPrefix(redirectURL, "http://127.0.0.1:") || !strings.HasSuffix(redirectURL, "/callback") {
+		t.Errorf("redirectURL = %s", redirectURL)
+	}
+
+	// the listener is gone once the flow ended
+	if resp, err := loopbackClient.Get(redirectURL); err == nil {
+		resp.Body.Close()
+		t.Error("the loopback listener still accepts connections")
+	}
+}
+
+func TestAuthWithOAuth2LoopbackProviderError(t *testing.T) {
+	server := newOAuth2Server(t)
+	client := New(server.URL)
+
+	_, err := client.Collection("users").AuthWithOAuth2Loopback(context.Background(), OAuth2LoopbackOptions{
+		Provider: "test",
+		OpenURL: func(authURL string) error {
+			// a forged callback is rejected and doesn't end the flow
+			if got := callback(t, authURL, url.Values{"state": {"forged"}, "code": {"stolen"}}); got != http.StatusBadRequest {
+				t.Errorf("forged callback status = %d, want %d", got, http.StatusBadRequest)
+			}
+			if got := callback(t, authURL, url.Values{"error": {"access_denied"}, "error_description": {"user said no"}}); got != http.StatusBadRequest {
+				t.Errorf("error callback status = %d, want %d", got, http.StatusBadRequest)
+			}
+			return nil
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "access_denied: user said no") {
+		t.Fatalf("AuthWithOAuth2Loopback() error = %v, want the provider error", err)
+	}
+	if got := server.codeExchanges(); len(got) != 0 {
+		t.Errorf("%d code exchanges, want none", len(got))
+	}
+}
+
+func TestAuthWithOAuth2LoopbackTimeout(t *testing.T) {
+	server := newOAuth2Server(t)
+	client := New(server.URL)
+
+	var redirectURL string
+	start := time.Now()
+	_, err := client.Collection("users").AuthWithOAuth2Loopback(context.Background(), OAuth2LoopbackOptions{
+		Provider: "test",
+		Timeout:  100 * time.Millisecond,
+		OpenURL: func(authURL string) error {
+			u, _ := url.Parse(authURL)
+			redirectURL = u.Query().Get("redirect_uri")
+			return nil
+		},
+	})
+	if !IsAbort(err) {
+		t.Fatalf("AuthWithOAuth2Loopback() error = %v, want an abort error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("timed out after %s", elapsed)
+	}
+	if resp, err := loopbackClient.Get(redirectURL); err == nil {
+		resp.Body.Close()
+		t.Error("the loopback listener still accepts connections after a timeout")
+	}
+}
@@ -1,11 +1,13 @@
 package bosbase
 
 import (
+    "context"
     "encoding/json"
     "errors"
     "fmt"
     "net/url"
     "sync"
+    "sync/atomic"
     "time"
 
     "github.com/gorilla/websocket"
@@ -24,9 +26,21 @@ type PublishAck struct {
     Created string
 }
 
+// pubsubPending is a request waiting for its ack. Queued publishes are not
+// sent yet and survive reconnects; sent requests fail when the connection
+// is lost, since their ack will never arrive.
 type pubsubPending struct {
-    ch    chan map[string]interface{}
-    timer *time.Timer
+    ch      chan map[string]interface{}
+    payload []byte
+    queued  bool
+}
+
+// pubsubSubscription is the subscribe request of a topic on the current
+// connection. It is shared by the resubscription of a (re)connect and a
+// concurrent Subscribe, so the server gets a single subscribe per topic.
+type pubsubSubscription struct {
+    done chan struct{}
+    err  error
 }
 
 type pubsubListener struct {
@@ -34,47 +48,77 @@ type pubsubListener struct {
     fn func(PubSubMessage)
 }
 
+// Defaults of the PubSubService options.
+const (
+    DefaultPubSubQueueSize      = 100
+    DefaultPubSubPublishTimeout = 10 * time.Second
+)
+
 type PubSubService struct {
     BaseService
+    // OnStateChange is called on every connection state change, with the
+    // same states as the realtime connection.
+    OnStateChange func(state RealtimeState, err error)
+    // OnError is called with errors that cannot be returned to a caller,
+    // e.g. failed resubscriptions after a reconnect.
+    OnError func(error)
+    // ReconnectPolicy configures the backoff between connection attempts.
+    ReconnectPolicy ReconnectPolicy
+    // QueueSize bounds the publishes queued while disconnected (defaults
+    // to DefaultPubSubQueueSize). Publish fails with ErrPubSubQueueFull
+    // beyond it.
+    QueueSize int
+    // PublishTimeout is the deadline of a Publish call, including the time
+    // spent queued, and of subscribe and unsubscribe requests (defaults to
+    // DefaultPubSubPublishTimeout).
+    PublishTimeout time.Duration
+
     conn     *websocket.Conn
     mu       sync.RWMutex
+    writeMu  sync.Mutex
     subs     map[string][]pubsubListener
+    // subSent holds the subscribe requests sent on the current connection
+    subSent  map[string]*pubsubSubscription
     pending  map[string]*pubsubPending
+    queue    []string
     isReady  bool
     clientID string
     counter  int64
+    requests int64
+    running  bool
+    stopCh   chan struct{}
+    readyCh  chan struct{}
+    state    RealtimeState
 }
 
 func NewPubSubService(client *BosBase) *PubSubService {
-    return &PubSubService{
-        BaseService: BaseService{client: client},
-        subs:        map[string][]pubsubListener{},
-        pending:     map[string]*pubsubPending{},
-    }
+    return &PubSubService{BaseService: BaseService{client: client}}
 }
 
 func (p *PubSubService) Publish(topic string, data interface{}) (PublishAck, error) {
+    return p.PublishCtx(context.Background(), topic, data)
+}
+
+// PublishCtx publishes data to topic and waits for the server ack. While
+// disconnected the message is queued and sent after reconnecting; ctx (or
+// PublishTimeout when ctx has no deadline) bounds the whole wait.
+func (p *PubSubService) PublishCtx(ctx context.Context, topic string, data interface{}) (PublishAck, error) {
     if topic == "" {
         return PublishAck{}, errors.New("topic must be set")
     }
-    if err := p.ensureSocket(); err != nil {
-        return PublishAck{}, err
+    if _, ok := ctx.Deadline(); !ok {
+        var cancel context.CancelFunc
+        ctx, cancel = context.WithTimeout(ctx, p.requestTimeout())
+        defer cancel()
     }
-    reqID := p.nextRequestID()
-    ackCh := p.waitForAck(reqID)
-    envelope := map[string]interface{}{
-        "type":      "publish",
-        "topic":     topic,
-        "data":      data,
-        "requestId": reqID,
-    }
-    if err := p.sendEnvelope(envelope); err != nil {
+    payload, err := p.request(ctx, map[string]interface{}{
+        "type":  "publish",
+        "topic": topic,
+        "data":  data,
+    }, true)
+    if err != nil {
         return PublishAck{}, err
     }
-    payload := <-ackCh
-    if payload == nil {
-        return PublishAck{}, errors.New("missing publish ack")
-    }
     return PublishAck{ID: fmt.Sprint(payload["id"]), Topic: topic, Created: fmt.Sprint(payload["created"])}, nil
 }
 
@@ -86,6 +130,9 @@ func (p *PubSubService) Subscribe(topic string, callback func(PubSubMessage)) (f
         return nil, errors.New("callback must be set")
     }
     p.mu.Lock()
+    if p.subs == nil {
+        p.subs = map[string][]pubsubListener{}
+    }
     p.counter++
     listenerID := fmt.Sprintf("l-%d", p.counter)
     listeners := p.subs[topic]
@@ -94,84 +141,169 @@ func (p *PubSubService) Subscribe(topic string, callback func(PubSubMessage)) (f
     shouldSend := len(listeners) == 1
     p.mu.Unlock()
 
-    if err := p.ensureSocket(); err != nil {
-        return nil, err
-    }
+    unsubscribe := func() { p.removeListener(topic, listenerID) }
     if shouldSend {
-        reqID := p.nextRequestID()
-        ack := p.waitForAck(reqID)
-        _ = p.sendEnvelope(map[string]interface{}{"type": "subscribe", "topic": topic, "requestId": reqID})
-        <-ack
-    }
-
-    return func() {
-        p.mu.Lock()
-        listeners := p.subs[topic]
-        filtered := []pubsubListener{}
-        for _, entry := range listeners {
-            if entry.id == listenerID {
-                continue
-            }
-            filtered = append(filtered, entry)
-        }
-        if len(filtered) == 0 {
-            delete(p.subs, topic)
-            reqID := p.nextRequestID()
-            ack := p.waitForAck(reqID)
-            _ = p.sendEnvelope(map[string]interface{}{"type": "unsubscribe", "topic": topic, "requestId": reqID})
-            <-ack
-        } else {
-            p.subs[topic] = filtered
+        // a connection that becomes ready later resubscribes all topics
+        if err := p.subscribeTopic(topic); err != nil {
+            unsubscribe()
+            return nil, err
         }
-        p.mu.Unlock()
-        if !p.hasSubscriptions() {
-            p.Disconnect()
-        }
-    }, nil
+    } else {
+        p.ensureRunning()
+    }
+    return unsubscribe, nil
 }
 
 func (p *PubSubService) Unsubscribe(topic string) {
     if topic == "" {
         p.mu.Lock()
         p.subs = map[string][]pubsubListener{}
+        p.subSent = nil
         p.mu.Unlock()
-        _ = p.sendEnvelope(map[string]interface{}{"type": "unsubscribe"})
+        p.sendUnsubscribe("")
         p.Disconnect()
         return
     }
     p.mu.Lock()
-    if _, ok := p.subs[topic]; ok {
+    _, ok := p.subs[topic]
+    delete(p.subs, topic)
+    delete(p.subSent, topic)
+    p.mu.Unlock()
+    if ok {
+        p.sendUnsubscribe(topic)
+    }
+    if !p.needsConnection() {
+        p.Disconnect()
+    }
+}
+
+func (p *PubSubService) removeListener(topic, listenerID string) {
+    p.mu.Lock()
+    listeners := p.subs[topic]
+    filtered := []pubsubListener{}
+    for _, entry := range listeners {
+        if entry.id == listenerID {
+            continue
+        }
+        filtered = append(filtered, entry)
+    }
+    removed := len(filtered) == 0 && len(listeners) > 0
+    if len(filtered) == 0 {
         delete(p.subs, topic)
-        reqID := p.nextRequestID()
-        ack := p.waitForAck(reqID)
-        _ = p.sendEnvelope(map[string]interface{}{"type": "unsubscribe", "topic": topic, "requestId": reqID})
-        <-ack
+        delete(p.subSent, topic)
+    } else {
+        p.subs[topic] = filtered
     }
     p.mu.Unlock()
-    if !p.hasSubscriptions() {
+
+    if removed {
+        p.sendUnsubscribe(topic)
+    }
+    if !p.needsConnection() {
         p.Disconnect()
     }
 }
 
+// Disconnect closes the socket and stops reconnecting. Pending and queued
+// requests fail with ErrPubSubClosed.
 func (p *PubSubService) Disconnect() {
     p.mu.Lock()
-    if p.conn != nil {
-        _ = p.conn.Close()
-        p.conn = nil
+    wasRunning := p.running
+    if p.stopCh != nil {
+        close(p.stopCh)
+        p.stopCh = nil
     }
+    conn := p.conn
+    p.conn = nil
+    p.running = false
     p.isReady = false
-    p.pending = map[string]*pubsubPending{}
+    p.readyCh = nil
+    p.subSent = nil
+    p.failPendingLocked(false, ErrPubSubClosed)
     p.mu.Unlock()
+    if conn != nil {
+        _ = conn.Close()
+    }
+    if wasRunning {
+        p.setState(RealtimeClosed, nil)
+    }
 }
 
-func (p *PubSubService) ensureSocket() error {
+// State returns the current connection state.
+func (p *PubSubService) State() RealtimeState {
     p.mu.RLock()
-    if p.conn != nil && p.isReady {
-        p.mu.RUnlock()
-        return nil
+    defer p.mu.RUnlock()
+    return p.state
+}
+
+// IsConnected reports whether the socket is connected and ready.
+func (p *PubSubService) IsConnected() bool {
+    p.mu.RLock()
+    defer p.mu.RUnlock()
+    return p.isReady
+}
+
+func (p *PubSubService) ensureRunning() {
+    p.mu.Lock()
+    if p.running {
+        p.mu.Unlock()
+        return
     }
-    p.mu.RUnlock()
+    stopCh := make(chan struct{})
+    p.stopCh = stopCh
+    p.readyCh = make(chan struct{})
+    p.running = true
+    p.mu.Unlock()
+    p.setState(RealtimeConnecting, nil)
+    go p.run(stopCh)
+}
+
+// run keeps the socket open until stopCh is closed, reconnecting according
+// to ReconnectPolicy while there are subscriptions or pending publishes.
+func (p *PubSubService) run(stopCh chan struct{}) {
+    attempt := 0
+    for {
+        select {
+        case <-stopCh:
+            return
+        default:
+        }
+
+        err := p.connect(stopCh)
+        select {
+        case <-stopCh:
+            return
+        default:
+        }
+        if err == nil {
+            // the connection was established and then lost
+            attempt = 0
+            if !p.needsConnection() {
+                p.stop(stopCh, nil)
+                return
+            }
+            err = errors.New("pubsub connection lost")
+        }
+
+        attempt++
+        policy := p.ReconnectPolicy
+        if policy.MaxAttempts > 0 && attempt > policy.MaxAttempts {
+            p.stop(stopCh, fmt.Errorf("%w: giving up after %d attempts: %v", ErrPubSubClosed, policy.MaxAttempts, err))
+            return
+        }
+        p.setState(RealtimeReconnecting, err)
+
+        select {
+        case <-stopCh:
+            return
+        case <-time.After(policy.delay(attempt)):
+        }
+    }
+}
 
+// connect runs a single socket connection. It returns nil when an
+// established (ready) connection ends and the dial or read error otherwise.
+func (p *PubSubService) connect(stopCh chan struct{}) error {
     wsURL, err := p.buildWSURL()
     if err != nil {
         return err
@@ -180,14 +312,93 @@ func (p *PubSubService) ensureSocket() error {
     if err != nil {
         return err
     }
+
     p.mu.Lock()
+    select {
+    case <-stopCh:
+        p.mu.Unlock()
+        _ = conn.Close()
+        return nil
+    default:
+    }
     p.conn = conn
     p.isReady = false
     p.mu.Unlock()
-    go p.listen()
+
+    ready, err := p.listen(conn)
+
+    p.mu.Lock()
+    if p.conn == conn {
+        p.conn = nil
+    }
+    p.isReady = false
+    p.clientID = ""
+    p.subSent = nil
+    if p.readyCh != nil {
+        select {
+        case <-p.readyCh:
+            p.readyCh = make(chan struct{})
+        default:
+        }
+    }
+    p.failPendingLocked(true, errors.New("pubsub connection lost before the request was acknowledged"))
+    p.mu.Unlock()
+    _ = conn.Close()
+
+    if !ready {
+        return err
+    }
     return nil
 }
 
+// stop closes the connection loop after run gave up or has nothing left to
+// do, unless it was already replaced by Disconnect or a new loop.
+func (p *PubSubService) stop(stopCh chan struct{}, err error) {
+    p.mu.Lock()
+    if p.stopCh != stopCh {
+        p.mu.Unlock()
+        return
+    }
+    close(stopCh)
+    p.stopCh = nil
+    p.running = false
+    p.readyCh = nil
+    if err != nil {
+        p.failPendingLocked(false, err)
+    }
+    p.mu.Unlock()
+    p.setState(RealtimeClosed, err)
+    if err != nil {
+        p.reportError(err)
+    }
+}
+
+func (p *PubSubService) setState(state RealtimeState, err error) {
+    p.mu.Lock()
+    changed := p.state != state
+    p.state = state
+    p.mu.Unlock()
+    if (!changed && err == nil) || p.OnStateChange == nil {
+        return
+    }
+    defer func() { recover() }()
+    p.OnStateChange(state, err)
+}
+
+func (p *PubSubService) reportError(err error) {
+    if p.OnError == nil {
+        return
+    }
+    defer func() { recover() }()
+    p.OnError(err)
+}
+
+func (p *PubSubService) needsConnection() bool {
+    p.mu.RLock()
+    defer p.mu.RUnlock()
+    return len(p.subs) > 0 || len(p.pending) > 0
+}
+
 func (p *PubSubService) buildWSURL() (string, error) {
     query := map[string]interface{}{}
     if p.client.AuthStore != nil && p.client.AuthStore.IsValid() {
@@ -206,42 +417,102 @@ func (p *PubSubService) buildWSURL() (string, error) {
     return u.String(), nil
 }
 
-func (p *PubSubService) listen() {
+// listen reads conn until it fails and reports whether it became ready.
+func (p *PubSubService) listen(conn *websocket.Conn) (bool, error) {
+    ready := false
     for {
-        p.mu.RLock()
-        conn := p.conn
-        p.mu.RUnlock()
-        if conn == nil {
-            return
-        }
         _, msg, err := conn.ReadMessage()
         if err != nil {
-            p.Disconnect()
-            return
+            return ready, err
         }
         var data map[string]interface{}
         if err := json.Unmarshal(msg, &data); err != nil {
             continue
         }
+        if fmt.Sprint(data["type"]) == "ready" {
+            ready = true
+            p.handleReady(conn, data)
+            continue
+        }
         p.handleMessage(data)
     }
 }
 
+// handleReady flushes the queued publishes in order, before any new publish
+// can be written, and resubscribes all topics, including the ones whose
+// Subscribe is waiting for this connection.
+func (p *PubSubService) handleReady(conn *websocket.Conn, data map[string]interface{}) {
+    p.writeMu.Lock()
+    p.mu.Lock()
+    p.clientID = fmt.Sprint(data["clientId"])
+    p.isReady = true
+    if p.readyCh != nil {
+        select {
+        case <-p.readyCh:
+        default:
+            close(p.readyCh)
+        }
+    }
+    var queued [][]byte
+    for _, reqID := range p.queue {
+        if pending := p.pending[reqID]; pending != nil && pending.queued {
+            pending.queued = false
+            queued = append(queued, pending.payload)
+        }
+    }
+    p.queue = nil
+    // the subscribes are written once the queued publishes are flushed
+    p.subSent = nil
+    topics := p.getTopicsLocked()
+    subs := make([]*pubsubSubscription, len(topics))
+    for i, topic := range topics {
+        subs[i] = p.subscribeLocked(topic)
+    }
+    p.mu.Unlock()
+
+    for _, payload := range queued {
+        if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+            // the read loop fails next and rejects the sent requests
+            break
+        }
+    }
+    p.writeMu.Unlock()
+    p.setState(RealtimeConnected, nil)
+
+    // acks are read by this goroutine, so don't wait for them here
+    go func() {
+        for i, sub := range subs {
+            <-sub.done
+            if sub.err != nil {
+                p.reportError(fmt.Errorf("pubsub: failed to resubscribe to %s: %w", topics[i], sub.err))
+            }
+        }
+    }()
+}
+
+// subscribeLocked returns the subscribe request of topic on the current
+// connection, sending it unless it was already sent. p.mu must be held.
+func (p *PubSubService) subscribeLocked(topic string) *pubsubSubscription {
+    if sub := p.subSent[topic]; sub != nil {
+        return sub
+    }
+    sub := &pubsubSubscription{done: make(chan struct{})}
+    if p.subSent == nil {
+        p.subSent = map[string]*pubsubSubscription{}
+    }
+    p.subSent[topic] = sub
+    go func() {
+        defer close(sub.done)
+        ctx, cancel := context.WithTimeout(context.Background(), p.requestTimeout())
+        defer cancel()
+        _, sub.err = p.request(ctx, map[string]interface{}{"type": "subscribe", "topic": topic}, false)
+    }()
+    return sub
+}
+
 func (p *PubSubService) handleMessage(data map[string]interface{}) {
     msgType := fmt.Sprint(data["type"])
     switch msgType {
-    case "ready":
-        p.mu.Lock()
-        p.clientID = fmt.Sprint(data["clientId"])
-        p.isReady = true
-        topics := p.getTopicsLocked()
-        p.mu.Unlock()
-        for _, topic := range topics {
-            reqID := p.nextRequestID()
-            ack := p.waitForAck(reqID)
-            _ = p.sendEnvelope(map[string]interface{}{"type": "subscribe", "topic": topic, "requestId": reqID})
-            <-ack
-        }
     case "message":
         topic := fmt.Sprint(data["topic"])
         message := PubSubMessage{ID: fmt.Sprint(data["id"]), Topic: topic, Created: fmt.Sprint(data["created"]), Data: data["data"]}
@@ -250,7 +521,11 @@ func (p *PubSubService) handleMessage(data map[string]interface{}) {
         p.mu.RUnlock()
         for _, entry := range listeners {
             func(cb func(PubSubMessage)) {
-                defer func() { recover() }()
+                defer func() {
+                    if rec := recover(); rec != nil {
+                        p.reportError(fmt.Errorf("pubsub: panic in %s subscription callback: %v", topic, rec))
+                    }
+                }()
                 cb(message)
             }(entry.fn)
         }
@@ -260,38 +535,145 @@ func (p *PubSubService) handleMessage(data map[string]interface{}) {
         }
     case "error":
         if reqID, ok := data["requestId"].(string); ok {
-            p.rejectPending(reqID, &ClientResponseError{Response: map[string]interface{}{"message": fmt.Sprint(data["message"])}})
+            p.rejectPending(reqID, newClientError("", 0, fmt.Sprint(data["message"])))
         }
     }
 }
 
-func (p *PubSubService) sendEnvelope(data map[string]interface{}) error {
-    if err := p.ensureSocket(); err != nil {
+// subscribeTopic waits for the connection and its subscribe to topic, which
+// is sent by handleReady when the topic was added before the connection
+// became ready.
+func (p *PubSubService) subscribeTopic(topic string) error {
+    p.ensureRunning()
+    ctx, cancel := context.WithTimeout(context.Background(), p.requestTimeout())
+    defer cancel()
+    if err := p.waitReady(ctx); err != nil {
         return err
     }
+    p.mu.Lock()
+    sub := p.subscribeLocked(topic)
+    p.mu.Unlock()
+    select {
+    case <-sub.done:
+        return sub.err
+    case <-ctx.Done():
+        return newAbortError("", ctx.Err())
+    }
+}
+
+func (p *PubSubService) sendUnsubscribe(topic string) {
+    if !p.IsConnected() {
+        return
+    }
+    envelope := map[string]interface{}{"type": "unsubscribe"}
+    if topic != "" {
+        envelope["topic"] = topic
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), p.requestTimeout())
+    defer cancel()
+    if _, err := p.request(ctx, envelope, false); err != nil && !errors.Is(err, ErrPubSubClosed) {
+        p.reportError(fmt.Errorf("pubsub: failed to unsubscribe: %w", err))
+    }
+}
+
+// requestTimeout returns the configured PublishTimeout or its default.
+func (p *PubSubService) requestTimeout() time.Duration {
+    if p.PublishTimeout > 0 {
+        return p.PublishTimeout
+    }
+    return DefaultPubSubPublishTimeout
+}
+
+func (p *PubSubService) waitReady(ctx context.Context) error {
     p.mu.RLock()
-    conn := p.conn
+    readyCh := p.readyCh
+    stopCh := p.stopCh
     p.mu.RUnlock()
-    if conn == nil {
-        return errors.New("pubsub connection not initialized")
+    if readyCh == nil {
+        return ErrPubSubClosed
+    }
+    select {
+    case <-readyCh:
+        return nil
+    case <-stopCh:
+        return ErrPubSubClosed
+    case <-ctx.Done():
+        return newAbortError("", ctx.Err())
     }
-    payload, _ := json.Marshal(data)
-    return conn.WriteMessage(websocket.TextMessage, payload)
 }
 
-func (p *PubSubService) waitForAck(requestID string) <-chan map[string]interface{} {
-    ch := make(chan map[string]interface{}, 1)
-    timer := time.AfterFunc(10*time.Second, func() { ch <- nil })
+// request sends envelope with a new requestId and waits for its ack. With
+// queue set the envelope is queued while disconnected; otherwise it fails.
+func (p *PubSubService) request(ctx context.Context, envelope map[string]interface{}, queue bool) (map[string]interface{}, error) {
+    if queue {
+        p.ensureRunning()
+    }
+    reqID := p.nextRequestID()
+    envelope["requestId"] = reqID
+    payload, err := json.Marshal(envelope)
+    if err != nil {
+        return nil, err
+    }
+    pending := &pubsubPending{ch: make(chan map[string]interface{}, 1), payload: payload}
+
+    p.writeMu.Lock()
     p.mu.Lock()
-    p.pending[requestID] = &pubsubPending{ch: ch, timer: timer}
+    conn := p.conn
+    if !p.isReady || conn == nil {
+        conn = nil
+        if !queue {
+            p.mu.Unlock()
+            p.writeMu.Unlock()
+            return nil, errors.New("pubsub connection not ready")
+        }
+        size := p.QueueSize
+        if size <= 0 {
+            size = DefaultPubSubQueueSize
+        }
+        if len(p.queue) >= size {
+            p.mu.Unlock()
+            p.writeMu.Unlock()
+            return nil, ErrPubSubQueueFull
+        }
+        pending.queued = true
+        p.queue = append(p.queue, reqID)
+    }
+    if p.pending == nil {
+        p.pending = map[string]*pubsubPending{}
+    }
+    p.pending[reqID] = pending
     p.mu.Unlock()
-    return ch
+    if conn != nil {
+        err = conn.WriteMessage(websocket.TextMessage, payload)
+    }
+    p.writeMu.Unlock()
+    if err != nil {
+        p.rejectPending(reqID, err)
+    }
+
+    select {
+    case data := <-pending.ch:
+        if err, ok := data["error"].(error); ok {
+            return nil, err
+        }
+        return data, nil
+    case <-ctx.Done():
+        p.mu.Lock()
+        delete(p.pending, reqID)
+        for i, id := range p.queue {
+            if id == reqID {
+                p.queue = append(p.queue[:i:i], p.queue[i+1:]...)
+                break
+            }
+        }
+        p.mu.Unlock()
+        return nil, newAbortError("", ctx.Err())
+    }
 }
 
 func (p *PubSubService) resolvePending(requestID string, payload map[string]interface{}) {
     p.mu.Lock()
     if pending := p.pending[requestID]; pending != nil {
-        pending.timer.Stop()
         pending.ch <- payload
         delete(p.pending, requestID)
     }
@@ -301,17 +683,25 @@ func (p *PubSubService) resolvePending(requestID string, payload map[string]inte
 func (p *PubSubService) rejectPending(requestID string, err error) {
     p.mu.Lock()
     if pending := p.pending[requestID]; pending != nil {
-        pending.timer.Stop()
         pending.ch <- map[string]interface{}{"error": err}
         delete(p.pending, requestID)
     }
     p.mu.Unlock()
 }
 
-func (p *PubSubService) hasSubscriptions() bool {
-    p.mu.RLock()
-    defer p.mu.RUnlock()
-    return len(p.subs) > 0
+// failPendingLocked rejects the pending requests, only the already sent ones
+// when sentOnly is set. p.mu must be held.
+func (p *PubSubService) failPendingLocked(sentOnly bool, err error) {
+    for reqID, pending := range p.pending {
+        if sentOnly && pending.queued {
+            continue
+        }
+        pending.ch <- map[string]interface{}{"error": err}
+        delete(p.pending, reqID)
+    }
+    if !sentOnly {
+        p.queue = nil
+    }
 }
 
 func (p *PubSubService) getTopicsLocked() []string {
@@ -323,6 +713,6 @@ func (p *PubSubService) getTopicsLocked() []string {
 }
 
 func (p *PubSubService) nextRequestID() string {
-    p.counter++
-    return fmt.Sprintf("%d", time.Now().UnixNano()+p.counter)
+    n := atomic.AddInt64(&p.requests, 1)
+    return fmt.Sprintf("%d", time.Now().UnixNano()+n)
 }
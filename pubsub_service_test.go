@@ -0,0 +1,311 @@
+package bosbase
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// pubsubServer acks every request, except those of type ignore, and counts
+// the subscribes per topic. A set gate delays the ready message.
+type pubsubServer struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	subscribes map[string]int
+	published  []interface{}
+	conns      []*websocket.Conn
+	gate       chan struct{}
+	ignore     string
+}
+
+func newPubSubServer(t *testing.T) *pubsubServer {
+	s := &pubsubServer{subscribes: map[string]int{}}
+	upgrader := websocket.Upgrader{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		gate := s.gate
+		s.mu.Unlock()
+		if gate != nil {
+			select {
+			case <-gate:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		conn.WriteJSON(map[string]interface{}{"type": "ready", "clientId": "c1"})
+		for {
+			var msg map[string]interface{}
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			s.mu.Lock()
+			ignore := s.ignore == msg["type"]
+			s.mu.Unlock()
+			if ignore {
+				continue
+			}
+			ack := map[string]interface{}{"requestId": msg["requestId"]}
+			switch msg["type"] {
+			case "subscribe":
+				s.mu.Lock()
+				s.subscribes[msg["topic"].(string)]++
+				s.mu.Unlock()
+				ack["type"] = "subscribed"
+			case "unsubscribe":
+				ack["type"] = "unsubscribed"
+			case "publish":
+				s.mu.Lock()
+				s.published = append(s.published, msg["data"])
+				s.mu.Unlock()
+				ack["type"] = "published"
+				ack["id"] = "m1"
+			}
+			if err := conn.WriteJSON(ack); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *pubsubServer) count(topic string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.subscribes[topic]
+}
+
+func (s *pubsubServer) publishedData() []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]interface{}(nil), s.published...)
+}
+
+// holdReady makes new connections wait for the returned release func
+// before they become ready.
+func (s *pubsubServer) holdReady() (release func()) {
+	gate := make(chan struct{})
+	s.mu.Lock()
+	s.gate = gate
+	s.mu.Unlock()
+	return func() {
+		s.mu.Lock()
+		s.gate = nil
+		s.mu.Unlock()
+		close(gate)
+	}
+}
+
+func (s *pubsubServer) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+func TestPubSubSubscribeOncePerTopic(t *testing.T) {
+	server := newPubSubServer(t)
+	client := New(server.URL)
+	client.PubSub.ReconnectPolicy = ReconnectPolicy{InitialDelay: 10 * time.Millisecond}
+	defer client.PubSub.Disconnect()
+
+	topics := []string{"a", "b", "c"}
+	var wg sync.WaitGroup
+	for _, topic := range topics {
+		wg.Add(1)
+		go func(topic string) {
+			defer wg.Done()
+			if _, err := client.PubSub.Subscribe(topic, func(PubSubMessage) {}); err != nil {
+				t.Errorf("Subscribe(%s) error = %v", topic, err)
+			}
+		}(topic)
+	}
+	wg.Wait()
+	// subscribing to a topic of a ready connection
+	if _, err := client.PubSub.Subscribe("d", func(PubSubMessage) {}); err != nil {
+		t.Fatalf("Subscribe(d) error = %v", err)
+	}
+	topics = append(topics, "d")
+	for _, topic := range topics {
+		if got := server.count(topic); got != 1 {
+			t.Errorf("%d subscribes sent for %s on the first connect, want 1", got, topic)
+		}
+	}
+
+	// a reconnect resubscribes every topic once
+	server.dropConnections()
+	deadline := time.Now().Add(5 * time.Second)
+	for _, topic := range topics {
+		for server.count(topic) < 2 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	for _, topic := range topics {
+		if got := server.count(topic); got != 2 {
+			t.Errorf("%d subscribes sent for %s after a reconnect, want 2", got, topic)
+		}
+	}
+}
+
+func queueLen(p *PubSubService) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.queue)
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+func TestPubSubFlushesQueuedPublishesInOrder(t *testing.T) {
+	server := newPubSubServer(t)
+	client := New(server.URL)
+	client.PubSub.ReconnectPolicy = ReconnectPolicy{InitialDelay: 10 * time.Millisecond}
+	defer client.PubSub.Disconnect()
+
+	if _, err := client.PubSub.Subscribe("t", func(PubSubMessage) {}); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	release := server.holdReady()
+	server.dropConnections()
+	waitFor(t, "the connection to drop", func() bool { return !client.PubSub.IsConnected() })
+
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		go func(i int) {
+			_, err := client.PubSub.Publish("t", i)
+			errs <- err
+		}(i)
+		waitFor(t, "the publish to be queued", func() bool { return queueLen(client.PubSub) == i+1 })
+	}
+	release()
+	for i := 0; i < 5; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("Publish() error = %v", err)
+		}
+	}
+	got := server.publishedData()
+	want := []interface{}{0.0, 1.0, 2.0, 3.0, 4.0}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("published %v, want %v", got, want)
+	}
+}
+
+func TestPubSubQueueFull(t *testing.T) {
+	server := newPubSubServer(t)
+	release := server.holdReady()
+	client := New(server.URL)
+	client.PubSub.QueueSize = 2
+	defer client.PubSub.Disconnect()
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func(i int) {
+			_, err := client.PubSub.Publish("t", i)
+			errs <- err
+		}(i)
+	}
+	waitFor(t, "the queue to fill", func() bool { return queueLen(client.PubSub) == 2 })
+	if _, err := client.PubSub.Publish("t", 2); !errors.Is(err, ErrPubSubQueueFull) {
+		t.Errorf("Publish() error = %v, want ErrPubSubQueueFull", err)
+	}
+
+	release()
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("queued Publish() error = %v", err)
+		}
+	}
+	if got := server.publishedData(); len(got) != 2 {
+		t.Errorf("published %v, want the 2 queued messages", got)
+	}
+}
+
+func TestPubSubPublishDeadlineWhileQueued(t *testing.T) {
+	server := newPubSubServer(t)
+	release := server.holdReady()
+	client := New(server.URL)
+	client.PubSub.PublishTimeout = 50 * time.Millisecond
+	defer client.PubSub.Disconnect()
+
+	if _, err := client.PubSub.Publish("t", "expired"); !IsAbort(err) {
+		t.Fatalf("Publish() error = %v, want an abort error", err)
+	}
+	if n := queueLen(client.PubSub); n != 0 {
+		t.Errorf("%d messages left in the queue after the deadline", n)
+	}
+
+	release()
+	client.PubSub.PublishTimeout = 0
+	if _, err := client.PubSub.Publish("t", "fresh"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if got := server.publishedData(); fmt.Sprint(got) != "[fresh]" {
+		t.Errorf("published %v, the expired message must not be sent", got)
+	}
+}
+
+func TestPubSubSubscribeUsesPublishTimeout(t *testing.T) {
+	server := newPubSubServer(t)
+	server.ignore = "subscribe"
+	client := New(server.URL)
+	client.PubSub.PublishTimeout = 100 * time.Millisecond
+	defer client.PubSub.Disconnect()
+
+	start := time.Now()
+	if _, err := client.PubSub.Subscribe("t", func(PubSubMessage) {}); !IsAbort(err) {
+		t.Fatalf("Subscribe() error = %v, want an abort error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Subscribe() returned after %s, PublishTimeout was ignored", elapsed)
+	}
+}
+
+func TestPubSubGivesUpAfterMaxAttempts(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	var mu sync.Mutex
+	var reported []error
+	client := New(server.URL)
+	client.PubSub.ReconnectPolicy = ReconnectPolicy{InitialDelay: 5 * time.Millisecond, MaxAttempts: 2}
+	client.PubSub.OnError = func(err error) {
+		mu.Lock()
+		reported = append(reported, err)
+		mu.Unlock()
+	}
+	defer client.PubSub.Disconnect()
+
+	if _, err := client.PubSub.Publish("t", "lost"); !errors.Is(err, ErrPubSubClosed) {
+		t.Fatalf("Publish() error = %v, want ErrPubSubClosed", err)
+	}
+	waitFor(t, "the closed state", func() bool { return client.PubSub.State() == RealtimeClosed })
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 || !errors.Is(reported[0], ErrPubSubClosed) {
+		t.Errorf("OnError got %v, want the give-up error", reported)
+	}
+}
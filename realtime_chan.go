@@ -0,0 +1,157 @@
+package bosbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// RecordAction is the action of a realtime record event.
+type RecordAction string
+
+const (
+	ActionCreate RecordAction = "create"
+	ActionUpdate RecordAction = "update"
+	ActionDelete RecordAction = "delete"
+)
+
+// RecordEvent is a realtime record change delivered by SubscribeChan.
+type RecordEvent struct {
+	Action RecordAction
+	Record Record
+}
+
+// OverflowPolicy decides what happens when a SubscribeChan buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDropOldest discards the oldest buffered event. It is the
+	// default, so a slow consumer never stalls other subscriptions.
+	OverflowDropOldest OverflowPolicy = iota
+	// OverflowError reports ErrSubscriptionOverflow to OnError and closes
+	// the subscription channel.
+	OverflowError
+	// OverflowBlock waits for the consumer, pausing delivery on the realtime
+	// connection for all topics. Only use it when the consumer is
+	// guaranteed to keep up.
+	OverflowBlock
+)
+
+// SubscribeOptions configures SubscribeChan.
+type SubscribeOptions struct {
+	// Buffer is the channel capacity (defaults to 64).
+	Buffer int
+	// Overflow is the policy for a full buffer (defaults to
+	// OverflowDropOldest).
+	Overflow OverflowPolicy
+	Query    map[string]interface{}
+	Headers  map[string]string
+}
+
+// SubscribeChan subscribes to topic and delivers its events on a buffered
+// channel, so a slow consumer never stalls the realtime connection unless
+// OverflowBlock is explicitly requested. The subscription is removed and the channel closed
+// once ctx is done (or on overflow with OverflowError).
+func (r *RealtimeService) SubscribeChan(ctx context.Context, topic string, opts SubscribeOptions) (<-chan RecordEvent, error) {
+	if opts.Buffer <= 0 {
+		opts.Buffer = 64
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	sub := &chanSubscription{
+		ctx:      ctx,
+		cancel:   cancel,
+		ch:       make(chan RecordEvent, opts.Buffer),
+		policy:   opts.Overflow,
+		topic:    topic,
+		realtime: r,
+	}
+
+	unsubscribe, err := r.subscribeCtx(ctx, topic, sub.deliver, opts.Query, opts.Headers)
+	if err != nil {
+		cancel()
+		if unsubscribe != nil {
+			unsubscribe()
+		}
+		return nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+		sub.close()
+	}()
+	return sub.ch, nil
+}
+
+// SubscribeChan subscribes to record changes of the collection, where topic
+// is "*" or a record id. See RealtimeService.SubscribeChan.
+func (s *RecordService) SubscribeChan(ctx context.Context, topic string, opts SubscribeOptions) (<-chan RecordEvent, error) {
+	if topic == "" {
+		return nil, errors.New("topic must be set")
+	}
+	return s.client.Realtime.SubscribeChan(ctx, s.collection+"/"+topic, opts)
+}
+
+type chanSubscription struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	ch       chan RecordEvent
+	policy   OverflowPolicy
+	topic    string
+	realtime *RealtimeService
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// deliver runs on the realtime reader goroutine.
+func (s *chanSubscription) deliver(payload map[string]interface{}) {
+	event := RecordEvent{}
+	if action, ok := payload["action"].(string); ok {
+		event.Action = RecordAction(action)
+	}
+	if record, ok := payload["record"].(map[string]interface{}); ok {
+		event.Record = Record(record)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	switch s.policy {
+	case OverflowBlock:
+		select {
+		case s.ch <- event:
+		case <-s.ctx.Done():
+		}
+	case OverflowError:
+		select {
+		case s.ch <- event:
+		default:
+			s.realtime.reportError(fmt.Errorf("%w: %s", ErrSubscriptionOverflow, s.topic))
+			s.cancel()
+		}
+	default:
+		for {
+			select {
+			case s.ch <- event:
+				return
+			default:
+			}
+			select {
+			case <-s.ch:
+			default:
+			}
+		}
+	}
+}
+
+func (s *chanSubscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
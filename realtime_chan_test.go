@@ -0,0 +1,83 @@
+package bosbase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestChanSubscription(policy OverflowPolicy, buffer int) (*chanSubscription, *[]error) {
+	realtime := New("http://127.0.0.1:0").Realtime
+	errs := &[]error{}
+	realtime.OnError = func(err error) { *errs = append(*errs, err) }
+	ctx, cancel := context.WithCancel(context.Background())
+	return &chanSubscription{
+		ctx:      ctx,
+		cancel:   cancel,
+		ch:       make(chan RecordEvent, buffer),
+		policy:   policy,
+		topic:    "posts/*",
+		realtime: realtime,
+	}, errs
+}
+
+func event(id string) map[string]interface{} {
+	return map[string]interface{}{"action": "update", "record": map[string]interface{}{"id": id}}
+}
+
+func TestSubscribeOptionsDefaultNeverBlocks(t *testing.T) {
+	var opts SubscribeOptions
+	if opts.Overflow != OverflowDropOldest {
+		t.Fatalf("the zero OverflowPolicy is %v, want OverflowDropOldest", opts.Overflow)
+	}
+
+	sub, _ := newTestChanSubscription(opts.Overflow, 2)
+	done := make(chan struct{})
+	go func() {
+		for _, id := range []string{"a", "b", "c", "d"} {
+			sub.deliver(event(id))
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("deliver blocked on a full buffer")
+	}
+	if first, second := <-sub.ch, <-sub.ch; first.Record.ID() != "c" || second.Record.ID() != "d" || first.Action != ActionUpdate {
+		t.Errorf("buffered %v, %v; want the newest events", first.Record, second.Record)
+	}
+}
+
+func TestOverflowError(t *testing.T) {
+	sub, errs := newTestChanSubscription(OverflowError, 1)
+	sub.deliver(event("a"))
+	sub.deliver(event("b"))
+	if len(*errs) != 1 || !errors.Is((*errs)[0], ErrSubscriptionOverflow) {
+		t.Fatalf("reported %v", *errs)
+	}
+	if sub.ctx.Err() == nil {
+		t.Error("the subscription should be cancelled on overflow")
+	}
+}
+
+func TestOverflowBlockWaitsForConsumer(t *testing.T) {
+	sub, _ := newTestChanSubscription(OverflowBlock, 1)
+	sub.deliver(event("a"))
+	done := make(chan struct{})
+	go func() {
+		sub.deliver(event("b"))
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("deliver should block until the consumer reads")
+	case <-time.After(50 * time.Millisecond):
+	}
+	<-sub.ch
+	<-done
+	if got := (<-sub.ch).Record.ID(); got != "b" {
+		t.Errorf("got %s", got)
+	}
+}
@@ -2,20 +2,80 @@ package bosbase
 
 import (
     "bufio"
+    "context"
     "encoding/json"
     "errors"
     "fmt"
+    "math"
+    "math/rand"
     "net/http"
     "net/url"
     "strings"
     "sync"
+    "sync/atomic"
     "time"
 )
 
+// RealtimeState is the state of the realtime connection.
+type RealtimeState int
+
+const (
+    RealtimeClosed RealtimeState = iota
+    RealtimeConnecting
+    RealtimeConnected
+    RealtimeReconnecting
+)
+
+func (s RealtimeState) String() string {
+    switch s {
+    case RealtimeConnecting:
+        return "connecting"
+    case RealtimeConnected:
+        return "connected"
+    case RealtimeReconnecting:
+        return "reconnecting"
+    default:
+        return "closed"
+    }
+}
+
+// ReconnectPolicy configures the exponential backoff between realtime
+// connection attempts. Zero fields use the defaults.
+type ReconnectPolicy struct {
+    // InitialDelay is the delay before the first reconnect (defaults to 200ms).
+    InitialDelay time.Duration
+    // MaxDelay caps the delay between attempts (defaults to 30s).
+    MaxDelay time.Duration
+    // Multiplier grows the delay after each failed attempt (defaults to 2).
+    Multiplier float64
+    // Jitter randomizes each delay by up to ±Jitter of its value, in [0, 1]
+    // (defaults to 0.2; use a negative value to disable it).
+    Jitter float64
+    // MaxAttempts is the number of consecutive failed attempts after which
+    // the connection is closed (0 retries forever).
+    MaxAttempts int
+}
+
+// DefaultRealtimeIdleTimeout is used when RealtimeService.IdleTimeout is zero.
+// The server closes idle connections after 5 minutes, so a silent connection
+// older than that is considered half-open.
+const DefaultRealtimeIdleTimeout = 6 * time.Minute
+
 type RealtimeService struct {
     BaseService
     ClientID     string
     OnDisconnect func([]string)
+    // OnError is called with errors that cannot be returned to a caller,
+    // e.g. panics in subscription callbacks or overflowing subscription channels.
+    OnError func(error)
+    // OnStateChange is called on every connection state change. err is the
+    // cause of a Reconnecting or Closed state, if any.
+    OnStateChange func(state RealtimeState, err error)
+    // ReconnectPolicy configures the backoff between connection attempts.
+    ReconnectPolicy ReconnectPolicy
+    // IdleTimeout forces a reconnect when nothing was received for that long
+    // (defaults to DefaultRealtimeIdleTimeout; negative disables it).
+    IdleTimeout time.Duration
 
     mu            sync.RWMutex
     subscriptions map[string][]realtimeListener
@@ -23,6 +83,23 @@ type RealtimeService struct {
     readyCh       chan struct{}
     running       bool
     counter       int64
+    state         RealtimeState
+    lastEventID   string
+    connCancel    context.CancelFunc
+    // authKey identifies the auth record of the current connection.
+    authKey        string
+    authStore      AuthStorer
+    authListenerID string
+    reconnectNow   bool
+    // authPending and authWorking hand auth changes to a single worker, so
+    // they are handled in order. authMu makes reading the token of a new
+    // connection and comparing a changed one with it mutually exclusive.
+    authPending bool
+    authWorking bool
+    authMu      sync.Mutex
+    // connectListeners are notified on every PB_CONNECT, after the
+    // subscriptions were resubmitted.
+    connectListeners map[string]func()
 }
 
 type realtimeListener struct {
@@ -31,13 +108,24 @@ type realtimeListener struct {
 }
 
 func NewRealtimeService(client *BosBase) *RealtimeService {
-    return &RealtimeService{
-        BaseService:   BaseService{client: client},
-        subscriptions: map[string][]realtimeListener{},
-    }
+    return &RealtimeService{BaseService: BaseService{client: client}}
 }
 
 func (r *RealtimeService) Subscribe(topic string, callback func(map[string]interface{}), query map[string]interface{}, headers map[string]string) (func(), error) {
+    unsubscribe, err := r.subscribeCtx(context.Background(), topic, callback, query, headers)
+    if err != nil {
+        // don't leave a listener behind that the caller cannot remove
+        if unsubscribe != nil {
+            unsubscribe()
+        }
+        return nil, err
+    }
+    return unsubscribe, nil
+}
+
+// subscribeCtx registers callback and waits for the connection. The returned
+// unsubscribe func is set even when err is not nil.
+func (r *RealtimeService) subscribeCtx(ctx context.Context, topic string, callback func(map[string]interface{}), query map[string]interface{}, headers map[string]string) (func(), error) {
     if topic == "" {
         return nil, errors.New("topic must be set")
     }
@@ -46,20 +134,26 @@ func (r *RealtimeService) Subscribe(topic string, callback func(map[string]inter
     }
     key := r.buildSubscriptionKey(topic, query, headers)
     r.mu.Lock()
+    if r.subscriptions == nil {
+        r.subscriptions = map[string][]realtimeListener{}
+    }
     r.counter++
     listenerID := fmt.Sprintf("l-%d", r.counter)
     listeners := r.subscriptions[key]
     listeners = append(listeners, realtimeListener{id: listenerID, fn: callback})
         r.subscriptions[key] = listeners
     r.mu.Unlock()
+    unsubscribe := func() { r.UnsubscribeByTopicAndID(topic, listenerID) }
 
     r.ensureThread()
-    if err := r.EnsureConnected(10 * time.Second); err != nil {
-        return nil, err
+    if err := r.EnsureConnectedCtx(ctx, 10*time.Second); err != nil {
+        return unsubscribe, err
+    }
+    if err := r.submitSubscriptions(ctx); err != nil {
+        return unsubscribe, err
     }
-    r.submitSubscriptions()
 
-    return func() { r.UnsubscribeByTopicAndID(topic, listenerID) }, nil
+    return unsubscribe, nil
 }
 
 func (r *RealtimeService) Unsubscribe(topic string) {
@@ -77,7 +171,7 @@ func (r *RealtimeService) Unsubscribe(topic string) {
     r.mu.Unlock()
 
     if has {
-        r.submitSubscriptions()
+        r.resubmit()
     } else {
         r.Disconnect()
     }
@@ -110,7 +204,7 @@ func (r *RealtimeService) UnsubscribeByTopicAndID(topic, id string) {
     r.mu.Unlock()
 
     if has {
-        r.submitSubscriptions()
+        r.resubmit()
     } else {
         r.Disconnect()
     }
@@ -118,19 +212,44 @@ func (r *RealtimeService) UnsubscribeByTopicAndID(topic, id string) {
 
 func (r *RealtimeService) Disconnect() {
     r.mu.Lock()
+    wasRunning := r.running
     if r.stopCh != nil {
         close(r.stopCh)
+        r.stopCh = nil
+    }
+    if r.connCancel != nil {
+        r.connCancel()
+        r.connCancel = nil
     }
     r.running = false
     r.readyCh = nil
     r.ClientID = ""
+    r.lastEventID = ""
     r.mu.Unlock()
+    r.unwatchAuth()
+
+    if wasRunning {
+        r.setState(RealtimeClosed, nil)
+    }
+}
+
+// State returns the current connection state.
+func (r *RealtimeService) State() RealtimeState {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    return r.state
 }
 
 func (r *RealtimeService) EnsureConnected(timeout time.Duration) error {
+    return r.EnsureConnectedCtx(context.Background(), timeout)
+}
+
+// EnsureConnectedCtx is like EnsureConnected but stops waiting once ctx is done.
+func (r *RealtimeService) EnsureConnectedCtx(ctx context.Context, timeout time.Duration) error {
     r.ensureThread()
     r.mu.RLock()
     readyCh := r.readyCh
+    stopCh := r.stopCh
     r.mu.RUnlock()
     if readyCh == nil {
         return errors.New("realtime connection not initialized")
@@ -138,8 +257,12 @@ func (r *RealtimeService) EnsureConnected(timeout time.Duration) error {
     select {
     case <-readyCh:
         return nil
+    case <-stopCh:
+        return newClientError("", 0, "Realtime connection closed")
+    case <-ctx.Done():
+        return newAbortError("", ctx.Err())
     case <-time.After(timeout):
-        return &ClientResponseError{Response: map[string]interface{}{"message": "Realtime connection not established"}}
+        return newClientError("", 0, "Realtime connection not established")
     }
 }
 
@@ -149,70 +272,247 @@ func (r *RealtimeService) ensureThread() {
         r.mu.Unlock()
         return
     }
-    r.stopCh = make(chan struct{})
+    stopCh := make(chan struct{})
+    r.stopCh = stopCh
     r.readyCh = make(chan struct{})
     r.running = true
     r.mu.Unlock()
-    go r.run()
+    r.watchAuth()
+    r.setState(RealtimeConnecting, nil)
+    go r.run(stopCh)
 }
 
-func (r *RealtimeService) run() {
-    backoff := []time.Duration{200 * time.Millisecond, 500 * time.Millisecond, time.Second, 2 * time.Second, 5 * time.Second}
+// run keeps the SSE connection open until stopCh is closed, reconnecting
+// according to ReconnectPolicy.
+func (r *RealtimeService) run(stopCh chan struct{}) {
     attempt := 0
     baseURL := r.client.BuildURL("/api/realtime", nil)
 
     for {
-        r.mu.RLock()
-        stopCh := r.stopCh
-        r.mu.RUnlock()
         select {
         case <-stopCh:
             return
         default:
         }
 
-        req, _ := http.NewRequest(http.MethodGet, baseURL, nil)
-        req.Header.Set("Accept", "text/event-stream")
-        req.Header.Set("Cache-Control", "no-store")
-        req.Header.Set("Accept-Language", r.client.Lang)
-        req.Header.Set("User-Agent", userAgent)
-        if r.client.AuthStore != nil && r.client.AuthStore.IsValid() {
-            req.Header.Set("Authorization", r.client.AuthStore.Token())
+        err := r.connect(stopCh, baseURL)
+        select {
+        case <-stopCh:
+            return
+        default:
         }
-
-        resp, err := r.client.httpClient.Do(req)
-        if err != nil || resp.StatusCode >= 400 {
-            r.handleDisconnect()
-            delay := backoff[min(attempt, len(backoff)-1)]
-            attempt++
-            time.Sleep(delay)
+        r.mu.Lock()
+        reconnectNow := r.reconnectNow
+        r.reconnectNow = false
+        r.mu.Unlock()
+        if reconnectNow {
+            // the auth state changed, reconnect right away with the new token
+            attempt = 0
+            r.setState(RealtimeReconnecting, nil)
             continue
         }
-        attempt = 0
-        r.listen(resp)
-        resp.Body.Close()
-        r.handleDisconnect()
-        if !r.hasSubscriptions() {
+        if err == nil {
+            // the connection was established and then lost
+            attempt = 0
+            if !r.hasSubscriptions() {
+                r.stop(stopCh, nil)
+                return
+            }
+            err = errors.New("realtime connection lost")
+        }
+
+        attempt++
+        policy := r.ReconnectPolicy
+        if policy.MaxAttempts > 0 && attempt > policy.MaxAttempts {
+            r.stop(stopCh, fmt.Errorf("realtime: giving up after %d attempts: %w", policy.MaxAttempts, err))
             return
         }
+        r.setState(RealtimeReconnecting, err)
+
+        select {
+        case <-stopCh:
+            return
+        case <-time.After(policy.delay(attempt)):
+        }
     }
 }
 
-func (r *RealtimeService) listen(resp *http.Response) {
+// connect runs a single SSE connection. It returns nil when an established
+// connection ends and the connect or stream error otherwise.
+func (r *RealtimeService) connect(stopCh chan struct{}, baseURL string) error {
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+
+    r.mu.Lock()
+    select {
+    case <-stopCh:
+        r.mu.Unlock()
+        return nil
+    default:
+    }
+    r.connCancel = cancel
+    r.reconnectNow = false
+    lastEventID := r.lastEventID
+    r.mu.Unlock()
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL, nil)
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Accept", "text/event-stream")
+    req.Header.Set("Cache-Control", "no-store")
+    req.Header.Set("Accept-Language", r.client.Lang)
+    req.Header.Set("User-Agent", userAgent)
+    if lastEventID != "" {
+        req.Header.Set("Last-Event-ID", lastEventID)
+    }
+    r.authMu.Lock()
+    authKey := ""
+    if r.client.AuthStore != nil && r.client.AuthStore.IsValid() {
+        token := r.client.AuthStore.Token()
+        req.Header.Set("Authorization", token)
+        authKey = realtimeAuthKey(token)
+    }
+    r.mu.Lock()
+    r.authKey = authKey
+    r.mu.Unlock()
+    r.authMu.Unlock()
+
+    idleTimeout := r.IdleTimeout
+    if idleTimeout == 0 {
+        idleTimeout = DefaultRealtimeIdleTimeout
+    }
+    var idle *time.Timer
+    var idleFired int32
+    if idleTimeout > 0 {
+        idle = time.AfterFunc(idleTimeout, func() {
+            atomic.StoreInt32(&idleFired, 1)
+            cancel()
+        })
+        defer idle.Stop()
+    }
+
+    // the stream is long-lived: only IdleTimeout limits it, never the
+    // client's request timeout
+    httpClient := r.client.httpClient
+    if httpClient == nil {
+        httpClient = &http.Client{}
+    } else if httpClient.Timeout != 0 {
+        clone := *httpClient
+        clone.Timeout = 0
+        httpClient = &clone
+    }
+
+    resp, err := httpClient.Do(req)
+    if err != nil {
+        if atomic.LoadInt32(&idleFired) == 1 {
+            return fmt.Errorf("realtime: no response within %s", idleTimeout)
+        }
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode >= 400 {
+        return newClientError(baseURL, resp.StatusCode, fmt.Sprintf("realtime connection failed with status %d", resp.StatusCode))
+    }
+
+    connected := r.listen(resp, idle, idleTimeout)
+    r.handleDisconnect()
+    if atomic.LoadInt32(&idleFired) == 1 {
+        return fmt.Errorf("realtime: no data received for %s", idleTimeout)
+    }
+    if !connected {
+        return errors.New("realtime connection closed before PB_CONNECT")
+    }
+    return nil
+}
+
+// stop marks the connection closed after run gave up or has nothing left to
+// do, unless it was already replaced by Disconnect or a new connection.
+func (r *RealtimeService) stop(stopCh chan struct{}, err error) {
+    r.mu.Lock()
+    if r.stopCh != stopCh {
+        r.mu.Unlock()
+        return
+    }
+    close(stopCh)
+    r.stopCh = nil
+    r.running = false
+    r.readyCh = nil
+    r.connCancel = nil
+    r.mu.Unlock()
+    r.unwatchAuth()
+    r.setState(RealtimeClosed, err)
+    if err != nil {
+        r.reportError(err)
+    }
+}
+
+func (r *RealtimeService) setState(state RealtimeState, err error) {
+    r.mu.Lock()
+    changed := r.state != state
+    r.state = state
+    r.mu.Unlock()
+    if (!changed && err == nil) || r.OnStateChange == nil {
+        return
+    }
+    defer func() { recover() }()
+    r.OnStateChange(state, err)
+}
+
+func (p ReconnectPolicy) delay(attempt int) time.Duration {
+    delay := p.InitialDelay
+    if delay <= 0 {
+        delay = 200 * time.Millisecond
+    }
+    maxDelay := p.MaxDelay
+    if maxDelay <= 0 {
+        maxDelay = 30 * time.Second
+    }
+    multiplier := p.Multiplier
+    if multiplier < 1 {
+        multiplier = 2
+    }
+    d := float64(delay) * math.Pow(multiplier, float64(attempt-1))
+    if d > float64(maxDelay) {
+        d = float64(maxDelay)
+    }
+    jitter := p.Jitter
+    if jitter == 0 {
+        jitter = 0.2
+    }
+    if jitter > 0 {
+        if jitter > 1 {
+            jitter = 1
+        }
+        d *= 1 + jitter*(2*rand.Float64()-1)
+    }
+    return time.Duration(d)
+}
+
+// listen reads the event stream until it ends and reports whether PB_CONNECT
+// was received. Every received line resets the idle timer.
+func (r *RealtimeService) listen(resp *http.Response, idle *time.Timer, idleTimeout time.Duration) bool {
     reader := bufio.NewReader(resp.Body)
+    connected := false
     event := map[string]string{"event": "message", "data": "", "id": ""}
     for {
         line, err := reader.ReadString('\n')
         if err != nil {
-            return
+            return connected
         }
-        select {
-        case <-r.stopCh:
-            return
-        default:
+        if idle != nil {
+            idle.Reset(idleTimeout)
         }
         line = strings.TrimRight(line, "\r\n")
         if line == "" {
+            if event["id"] != "" {
+                r.mu.Lock()
+                r.lastEventID = event["id"]
+                r.mu.Unlock()
+            }
+            if event["event"] == "PB_CONNECT" {
+                connected = true
+            }
             r.dispatchEvent(event)
             event = map[string]string{"event": "message", "data": "", "id": ""}
             continue
@@ -256,6 +556,12 @@ func (r *RealtimeService) dispatchEvent(evt map[string]string) {
             clientID = evt["id"]
         }
         r.ClientID = clientID
+        // listeners added once the connection is ready only follow later
+        // connects, they already see the state of this one
+        onConnect := make([]func(), 0, len(r.connectListeners))
+        for _, fn := range r.connectListeners {
+            onConnect = append(onConnect, fn)
+        }
         ready := r.readyCh
         if ready != nil {
             select {
@@ -265,9 +571,10 @@ func (r *RealtimeService) dispatchEvent(evt map[string]string) {
             }
         }
         r.mu.Unlock()
-        r.submitSubscriptions()
-        if r.OnDisconnect != nil {
-            r.OnDisconnect([]string{})
+        r.setState(RealtimeConnected, nil)
+        r.resubmit()
+        for _, fn := range onConnect {
+            fn()
         }
         return
     }
@@ -277,25 +584,160 @@ func (r *RealtimeService) dispatchEvent(evt map[string]string) {
     r.mu.RUnlock()
     for _, entry := range entries {
         func(cb func(map[string]interface{})) {
-            defer func() { recover() }()
+            defer func() {
+                if rec := recover(); rec != nil {
+                    r.reportError(fmt.Errorf("realtime: panic in %s subscription callback: %v", name, rec))
+                }
+            }()
             cb(payload)
         }(entry.fn)
     }
 }
 
-func (r *RealtimeService) submitSubscriptions() {
+func (r *RealtimeService) reportError(err error) {
+    if r.OnError == nil {
+        return
+    }
+    defer func() { recover() }()
+    r.OnError(err)
+}
+
+func (r *RealtimeService) submitSubscriptions(ctx context.Context) error {
     r.mu.RLock()
     clientID := r.ClientID
     subs := r.getActiveSubscriptionsLocked()
     r.mu.RUnlock()
     if clientID == "" || len(subs) == 0 {
-        return
+        return nil
     }
     payload := map[string]interface{}{
         "clientId":     clientID,
         "subscriptions": subs,
     }
-    _, _ = r.client.Send("/api/realtime", &RequestOptions{Method: http.MethodPost, Body: payload})
+    _, err := r.client.SendContext(ctx, "/api/realtime", &RequestOptions{Method: http.MethodPost, Body: payload})
+    return err
+}
+
+// resubmit posts the subscriptions when there is no caller to return the
+// error to, reporting failures to OnError.
+func (r *RealtimeService) resubmit() {
+    if err := r.submitSubscriptions(context.Background()); err != nil {
+        r.reportError(fmt.Errorf("realtime: failed to submit subscriptions: %w", err))
+    }
+}
+
+// onConnect registers fn to be called after every (re)connect.
+func (r *RealtimeService) onConnect(fn func()) (remove func()) {
+    r.mu.Lock()
+    if r.connectListeners == nil {
+        r.connectListeners = map[string]func(){}
+    }
+    r.counter++
+    id := fmt.Sprintf("c-%d", r.counter)
+    r.connectListeners[id] = fn
+    r.mu.Unlock()
+    return func() {
+        r.mu.Lock()
+        delete(r.connectListeners, id)
+        r.mu.Unlock()
+    }
+}
+
+// watchAuth follows the auth store while the connection is running.
+func (r *RealtimeService) watchAuth() {
+    store := r.client.AuthStore
+    if store == nil {
+        return
+    }
+    r.mu.Lock()
+    if r.authStore == store {
+        r.mu.Unlock()
+        return
+    }
+    previous, previousID := r.authStore, r.authListenerID
+    r.authStore = store
+    r.authListenerID = store.AddListener(func(token string, record map[string]interface{}) {
+        // Save callers must not wait for the network
+        r.mu.Lock()
+        r.authPending = true
+        start := !r.authWorking
+        r.authWorking = true
+        r.mu.Unlock()
+        if start {
+            go r.followAuth()
+        }
+    })
+    r.mu.Unlock()
+    if previous != nil {
+        previous.RemoveListener(previousID)
+    }
+}
+
+func (r *RealtimeService) unwatchAuth() {
+    r.mu.Lock()
+    store, id := r.authStore, r.authListenerID
+    r.authStore, r.authListenerID = nil, ""
+    r.mu.Unlock()
+    if store != nil {
+        store.RemoveListener(id)
+    }
+}
+
+// followAuth handles auth changes one at a time until none is pending.
+// Changes saved while one is handled are coalesced, since only the latest
+// auth state matters.
+func (r *RealtimeService) followAuth() {
+    for {
+        r.mu.Lock()
+        if !r.authPending {
+            r.authWorking = false
+            r.mu.Unlock()
+            return
+        }
+        r.authPending = false
+        r.mu.Unlock()
+        r.handleAuthChange()
+    }
+}
+
+// handleAuthChange re-posts the subscriptions under a refreshed token of the
+// same auth record. Any other change (login, logout, switching records)
+// requires a new connection, since the server rejects subscription requests
+// whose auth doesn't match the one of the connection.
+func (r *RealtimeService) handleAuthChange() {
+    r.authMu.Lock()
+    key := ""
+    if r.client.AuthStore != nil && r.client.AuthStore.IsValid() {
+        key = realtimeAuthKey(r.client.AuthStore.Token())
+    }
+
+    r.mu.Lock()
+    if !r.running {
+        r.mu.Unlock()
+        r.authMu.Unlock()
+        return
+    }
+    if key == r.authKey {
+        r.mu.Unlock()
+        r.authMu.Unlock()
+        r.resubmit()
+        return
+    }
+    r.reconnectNow = true
+    cancel := r.connCancel
+    r.mu.Unlock()
+    r.authMu.Unlock()
+    if cancel != nil {
+        cancel()
+    }
+}
+
+func realtimeAuthKey(token string) string {
+    claims, err := ParseTokenClaims(token)
+    if err != nil || claims.ID == "" {
+        return ""
+    }
+    return claims.CollectionID + "/" + claims.ID
 }
 
 func (r *RealtimeService) GetActiveSubscriptions() []string {
@@ -351,10 +793,3 @@ func (r *RealtimeService) buildSubscriptionKey(topic string, query map[string]in
     }
     return key
 }
-
-func min(a, b int) int {
-    if a < b {
-        return a
-    }
-    return b
-}
@@ -0,0 +1,192 @@
+package bosbase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sseServer is a fake realtime endpoint. Subscription posts are answered
+// with submitStatus.
+type sseServer struct {
+	*httptest.Server
+	connects     int32
+	submitStatus int32
+
+	mu    sync.Mutex
+	auths []string
+}
+
+// lastAuth returns the Authorization header of the latest connection.
+func (s *sseServer) lastAuth() (string, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.auths) == 0 {
+		return "", 0
+	}
+	return s.auths[len(s.auths)-1], len(s.auths)
+}
+
+func newSSEServer(t *testing.T) *sseServer {
+	s := &sseServer{submitStatus: http.StatusNoContent}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(int(atomic.LoadInt32(&s.submitStatus)))
+			return
+		}
+		n := atomic.AddInt32(&s.connects, 1)
+		s.mu.Lock()
+		s.auths = append(s.auths, r.Header.Get("Authorization"))
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "id:%d\nevent:PB_CONNECT\ndata:{\"clientId\":\"client%d\"}\n\n", n, n)
+		w.(http.Flusher).Flush()
+		ticker := time.NewTicker(50 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+				fmt.Fprint(w, ": ping\n\n")
+				w.(http.Flusher).Flush()
+			}
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestRealtimeStreamIgnoresRequestTimeout(t *testing.T) {
+	server := newSSEServer(t)
+	client := New(server.URL, WithTimeout(200*time.Millisecond))
+	defer client.Close()
+
+	var mu sync.Mutex
+	var states []RealtimeState
+	client.Realtime.OnStateChange = func(state RealtimeState, err error) {
+		mu.Lock()
+		states = append(states, state)
+		mu.Unlock()
+	}
+
+	unsubscribe, err := client.Realtime.Subscribe("posts/*", func(map[string]interface{}) {}, nil, nil)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer unsubscribe()
+
+	time.Sleep(700 * time.Millisecond)
+	if got := atomic.LoadInt32(&server.connects); got != 1 {
+		t.Errorf("connected %d times, want a single long-lived stream", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, state := range states {
+		if state == RealtimeReconnecting {
+			t.Fatalf("states = %v, the stream must not be cut by the request timeout", states)
+		}
+	}
+}
+
+func TestRealtimeSubscribeRemovesListenerOnError(t *testing.T) {
+	server := newSSEServer(t)
+	atomic.StoreInt32(&server.submitStatus, http.StatusBadRequest)
+	client := New(server.URL)
+	defer client.Close()
+
+	if _, err := client.Realtime.Subscribe("posts/*", func(map[string]interface{}) {}, nil, nil); err == nil {
+		t.Fatal("Subscribe() should return the submit error")
+	}
+	client.Realtime.mu.RLock()
+	left := len(client.Realtime.subscriptions)
+	client.Realtime.mu.RUnlock()
+	if left != 0 {
+		t.Errorf("%d subscriptions left after a failed Subscribe", left)
+	}
+}
+
+type ctxMarker struct{}
+
+func TestRealtimeSubscribePostUsesCallerContext(t *testing.T) {
+	server := newSSEServer(t)
+	var marked int32
+	client := New(server.URL, WithMiddleware(func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			if req.Method == http.MethodPost && ctx.Value(ctxMarker{}) != nil {
+				atomic.AddInt32(&marked, 1)
+			}
+			return next(ctx, req)
+		}
+	}))
+	defer client.Close()
+
+	ctx, stop := context.WithCancel(context.WithValue(context.Background(), ctxMarker{}, true))
+	defer stop()
+	if _, err := client.Realtime.SubscribeChan(ctx, "posts/*", SubscribeOptions{}); err != nil {
+		t.Fatalf("SubscribeChan() error = %v", err)
+	}
+	if got := atomic.LoadInt32(&marked); got != 1 {
+		t.Errorf("%d subscription posts sent with the caller ctx, want 1", got)
+	}
+
+	// a canceled ctx aborts the post instead of running in the background
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.Realtime.subscribeCtx(canceled, "tags/*", func(map[string]interface{}) {}, nil, nil); !IsAbort(err) {
+		t.Errorf("subscribeCtx() error = %v, want an abort error", err)
+	}
+}
+
+func TestRealtimeFollowsAuthChangesInOrder(t *testing.T) {
+	server := newSSEServer(t)
+	client := New(server.URL)
+	defer client.Close()
+
+	unsubscribe, err := client.Realtime.Subscribe("posts/*", func(map[string]interface{}) {}, nil, nil)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer unsubscribe()
+
+	login := func() {
+		client.AuthStore.Save(testToken(time.Now().Add(time.Hour), ""), map[string]interface{}{"id": "u1", "collectionId": "c1"})
+	}
+	logout := client.AuthStore.Clear
+	// waitIdentity waits for the connection to use the auth record key, and
+	// checks that no stale change replaces it afterwards.
+	waitIdentity := func(i int, key string) {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if auth, _ := server.lastAuth(); realtimeAuthKey(auth) == key {
+				break
+			}
+			if time.Now().After(deadline) {
+				auth, n := server.lastAuth()
+				t.Fatalf("iteration %d: connection %d uses %q, want auth %q", i, n, auth, key)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		time.Sleep(30 * time.Millisecond)
+		if auth, n := server.lastAuth(); realtimeAuthKey(auth) != key {
+			t.Fatalf("iteration %d: connection %d switched to %q", i, n, auth)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		login()
+		logout()
+		login()
+		waitIdentity(i, "c1/u1")
+
+		logout()
+		login()
+		logout()
+		waitIdentity(i, "")
+	}
+}
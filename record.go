@@ -0,0 +1,314 @@
+package bosbase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateTimeLayout is the format BosBase uses for datetime fields.
+const DateTimeLayout = "2006-01-02 15:04:05.000Z"
+
+var dateTimeLayouts = []string{
+	DateTimeLayout,
+	"2006-01-02 15:04:05Z",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	time.RFC3339Nano,
+	"2006-01-02",
+}
+
+// DateTime is a time.Time that marshals to and from BosBase's datetime format.
+// The zero value is encoded as an empty string, matching unset fields.
+type DateTime struct {
+	time.Time
+}
+
+// NewDateTime wraps t as a DateTime in UTC.
+func NewDateTime(t time.Time) DateTime {
+	return DateTime{Time: t.UTC()}
+}
+
+// ParseDateTime parses a BosBase datetime string. RFC 3339 values and plain
+// dates are accepted as well; an empty string yields the zero DateTime.
+func ParseDateTime(value string) (DateTime, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return DateTime{}, nil
+	}
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return DateTime{Time: t.UTC()}, nil
+		}
+	}
+	return DateTime{}, fmt.Errorf("bosbase: invalid datetime %q", value)
+}
+
+// String returns the datetime in DateTimeLayout or "" for the zero value.
+func (d DateTime) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.UTC().Format(DateTimeLayout)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d DateTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *DateTime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = DateTime{}
+		return nil
+	}
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	parsed, err := ParseDateTime(raw)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// GeoPoint is the value of a BosBase geoPoint field.
+type GeoPoint struct {
+	Lon float64 `json:"lon"`
+	Lat float64 `json:"lat"`
+}
+
+// Record is a dynamic record with typed accessors. Getters convert between
+// compatible JSON types and return the zero value for missing fields.
+type Record map[string]interface{}
+
+// ID returns the record id.
+func (r Record) ID() string {
+	return r.GetString("id")
+}
+
+// CollectionID returns the id of the record's collection.
+func (r Record) CollectionID() string {
+	return r.GetString("collectionId")
+}
+
+// CollectionName returns the name of the record's collection.
+func (r Record) CollectionName() string {
+	return r.GetString("collectionName")
+}
+
+// Get returns the raw field value.
+func (r Record) Get(key string) interface{} {
+	return r[key]
+}
+
+// Set assigns a field value. time.Time values are stored as DateTime so they
+// serialize in BosBase's format. r must be non-nil.
+func (r Record) Set(key string, value interface{}) {
+	if t, ok := value.(time.Time); ok {
+		value = NewDateTime(t)
+	}
+	r[key] = value
+}
+
+// GetString returns the field as a string.
+func (r Record) GetString(key string) string {
+	return toString(r[key])
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// GetInt returns the field as an int, truncating fractional numbers.
+func (r Record) GetInt(key string) int {
+	return int(r.GetFloat(key))
+}
+
+// GetFloat returns the field as a float64.
+func (r Record) GetFloat(key string) float64 {
+	switch v := r[key].(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case string:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f
+	case bool:
+		if v {
+			return 1
+		}
+	}
+	return 0
+}
+
+// GetBool returns the field as a bool. Non-zero numbers and the strings
+// accepted by strconv.ParseBool are converted.
+func (r Record) GetBool(key string) bool {
+	switch v := r[key].(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(strings.TrimSpace(v))
+		return b
+	case nil:
+		return false
+	default:
+		return r.GetFloat(key) != 0
+	}
+}
+
+// GetDateTime returns the field parsed as a BosBase datetime. Empty or
+// invalid values yield the zero time.
+func (r Record) GetDateTime(key string) time.Time {
+	switch v := r[key].(type) {
+	case DateTime:
+		return v.Time
+	case time.Time:
+		return v
+	case string:
+		d, _ := ParseDateTime(v)
+		return d.Time
+	}
+	return time.Time{}
+}
+
+// GetStringSlice returns a multi-value field (select, relation, file) as a
+// string slice. Single-value fields yield a one element slice.
+func (r Record) GetStringSlice(key string) []string {
+	value := r[key]
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if item == nil {
+				continue
+			}
+			result = append(result, toString(item))
+		}
+		return result
+	case string:
+		if v == "" {
+			return []string{}
+		}
+		return []string{v}
+	case nil:
+		return []string{}
+	}
+	return []string{toString(value)}
+}
+
+// GetGeoPoint returns a geoPoint field.
+func (r Record) GetGeoPoint(key string) GeoPoint {
+	switch v := r[key].(type) {
+	case GeoPoint:
+		return v
+	case *GeoPoint:
+		if v != nil {
+			return *v
+		}
+	case map[string]interface{}:
+		point := Record(v)
+		return GeoPoint{Lon: point.GetFloat("lon"), Lat: point.GetFloat("lat")}
+	}
+	return GeoPoint{}
+}
+
+// Expand returns the expanded relation records for name. Single relations
+// yield a one element slice; missing expands yield an empty slice.
+func (r Record) Expand(name string) []Record {
+	expand, _ := r["expand"].(map[string]interface{})
+	switch v := expand[name].(type) {
+	case map[string]interface{}:
+		return []Record{v}
+	case Record:
+		return []Record{v}
+	case []interface{}:
+		return toRecords(v)
+	case []Record:
+		return v
+	}
+	return []Record{}
+}
+
+func toRecords(items []interface{}) []Record {
+	result := make([]Record, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// GetRecord fetches a single record by id as a Record.
+func (s *RecordService) GetRecord(ctx context.Context, recordID string, opts *CrudViewOptions) (Record, error) {
+	item, err := s.GetOneCtx(ctx, recordID, opts)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// GetFirstRecord returns the first record matching the filter as a Record.
+func (s *RecordService) GetFirstRecord(ctx context.Context, filter string, opts *CrudViewOptions) (Record, error) {
+	item, err := s.GetFirstListItemCtx(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// GetRecordList retrieves a paginated list of Records.
+func (s *RecordService) GetRecordList(ctx context.Context, opts *CrudListOptions) (*ListResult[Record], error) {
+	data, err := s.GetListCtx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	page := Record(data)
+	items, _ := data["items"].([]interface{})
+	return &ListResult[Record]{
+		Page:       page.GetInt("page"),
+		PerPage:    page.GetInt("perPage"),
+		TotalItems: page.GetInt("totalItems"),
+		TotalPages: page.GetInt("totalPages"),
+		Items:      toRecords(items),
+	}, nil
+}
+
+// GetFullRecordList retrieves all records in batches as Records.
+func (s *RecordService) GetFullRecordList(ctx context.Context, batch int, opts *CrudListOptions) ([]Record, error) {
+	items, err := s.GetFullListCtx(ctx, batch, opts)
+	if err != nil {
+		return nil, err
+	}
+	return toRecords(items), nil
+}
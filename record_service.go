@@ -1,8 +1,7 @@
 package bosbase
 
 import (
-    "encoding/base64"
-    "encoding/json"
+    "context"
     "errors"
     "fmt"
     "net/http"
@@ -48,7 +47,11 @@ func (s *RecordService) Unsubscribe(topic string) {
 }
 
 func (s *RecordService) Update(recordID string, opts *CrudMutateOptions) (map[string]interface{}, error) {
-    item, err := s.BaseCrudService.Update(recordID, opts)
+    return s.UpdateCtx(context.Background(), recordID, opts)
+}
+
+func (s *RecordService) UpdateCtx(ctx context.Context, recordID string, opts *CrudMutateOptions) (map[string]interface{}, error) {
+    item, err := s.BaseCrudService.UpdateCtx(ctx, recordID, opts)
     if err != nil {
         return nil, err
     }
@@ -57,7 +60,11 @@ func (s *RecordService) Update(recordID string, opts *CrudMutateOptions) (map[st
 }
 
 func (s *RecordService) Delete(recordID string, opts *CrudDeleteOptions) error {
-    if err := s.BaseCrudService.Delete(recordID, opts); err != nil {
+    return s.DeleteCtx(context.Background(), recordID, opts)
+}
+
+func (s *RecordService) DeleteCtx(ctx context.Context, recordID string, opts *CrudDeleteOptions) error {
+    if err := s.BaseCrudService.DeleteCtx(ctx, recordID, opts); err != nil {
         return err
     }
     if s.isAuthRecord(recordID) {
@@ -67,6 +74,10 @@ func (s *RecordService) Delete(recordID string, opts *CrudDeleteOptions) error {
 }
 
 func (s *RecordService) GetCount(filter, expand, fields string, query map[string]interface{}, headers map[string]string) (int, error) {
+    return s.GetCountCtx(context.Background(), filter, expand, fields, query, headers)
+}
+
+func (s *RecordService) GetCountCtx(ctx context.Context, filter, expand, fields string, query map[string]interface{}, headers map[string]string) (int, error) {
     params := cloneQuery(query)
     if filter != "" {
         params["filter"] = filter
@@ -77,7 +88,7 @@ func (s *RecordService) GetCount(filter, expand, fields string, query map[string
     if fields != "" {
         params["fields"] = fields
     }
-    data, err := s.client.Send(s.basePath()+"/count", &RequestOptions{Query: params, Headers: headers})
+    data, err := s.client.SendContext(ctx, s.basePath()+"/count", &RequestOptions{Query: params, Headers: headers})
     if err != nil {
         return 0, err
     }
@@ -90,12 +101,16 @@ func (s *RecordService) GetCount(filter, expand, fields string, query map[string
 }
 
 func (s *RecordService) ListAuthMethods(fields string, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.ListAuthMethodsCtx(context.Background(), fields, query, headers)
+}
+
+func (s *RecordService) ListAuthMethodsCtx(ctx context.Context, fields string, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     params := cloneQuery(query)
     if fields == "" {
         fields = "mfa,otp,password,oauth2"
     }
     params["fields"] = fields
-    data, err := s.client.Send(s.baseCollectionPath()+"/auth-methods", &RequestOptions{Query: params, Headers: headers})
+    data, err := s.client.SendContext(ctx, s.baseCollectionPath()+"/auth-methods", &RequestOptions{Query: params, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -106,6 +121,10 @@ func (s *RecordService) ListAuthMethods(fields string, query map[string]interfac
 }
 
 func (s *RecordService) AuthWithPassword(identity, password, expand, fields string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.AuthWithPasswordCtx(context.Background(), identity, password, expand, fields, body, query, headers)
+}
+
+func (s *RecordService) AuthWithPasswordCtx(ctx context.Context, identity, password, expand, fields string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
@@ -119,9 +138,9 @@ func (s *RecordService) AuthWithPassword(identity, password, expand, fields stri
     if fields != "" {
         params["fields"] = fields
     }
-    data, err := s.client.Send(s.baseCollectionPath()+"/auth-with-password", &RequestOptions{Method: http.MethodPost, Body: payload, Query: params, Headers: headers})
+    data, err := s.client.SendContext(ctx, s.baseCollectionPath()+"/auth-with-password", &RequestOptions{Method: http.MethodPost, Body: payload, Query: params, Headers: headers})
     if err != nil {
-        return nil, err
+        return nil, s.mfaChallenge(err, "password")
     }
     if m, ok := data.(map[string]interface{}); ok {
         return s.authResponse(m), nil
@@ -130,6 +149,10 @@ func (s *RecordService) AuthWithPassword(identity, password, expand, fields stri
 }
 
 func (s *RecordService) AuthWithOAuth2Code(provider, code, codeVerifier, redirectURL string, createData map[string]interface{}, body map[string]interface{}, query map[string]interface{}, headers map[string]string, expand, fields string) (map[string]interface{}, error) {
+    return s.AuthWithOAuth2CodeCtx(context.Background(), provider, code, codeVerifier, redirectURL, createData, body, query, headers, expand, fields)
+}
+
+func (s *RecordService) AuthWithOAuth2CodeCtx(ctx context.Context, provider, code, codeVerifier, redirectURL string, createData map[string]interface{}, body map[string]interface{}, query map[string]interface{}, headers map[string]string, expand, fields string) (map[string]interface{}, error) {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
@@ -148,9 +171,9 @@ func (s *RecordService) AuthWithOAuth2Code(provider, code, codeVerifier, redirec
     if fields != "" {
         params["fields"] = fields
     }
-    data, err := s.client.Send(s.baseCollectionPath()+"/auth-with-oauth2", &RequestOptions{Method: http.MethodPost, Body: payload, Query: params, Headers: headers})
+    data, err := s.client.SendContext(ctx, s.baseCollectionPath()+"/auth-with-oauth2", &RequestOptions{Method: http.MethodPost, Body: payload, Query: params, Headers: headers})
     if err != nil {
-        return nil, err
+        return nil, s.mfaChallenge(err, "oauth2")
     }
     if m, ok := data.(map[string]interface{}); ok {
         return s.authResponse(m), nil
@@ -159,28 +182,14 @@ func (s *RecordService) AuthWithOAuth2Code(provider, code, codeVerifier, redirec
 }
 
 func (s *RecordService) AuthWithOAuth2(providerName string, urlCallback func(string), scopes []string, createData map[string]interface{}, body map[string]interface{}, query map[string]interface{}, headers map[string]string, expand, fields string, timeout time.Duration) (map[string]interface{}, error) {
-    methods, err := s.ListAuthMethods("", nil, nil)
+    return s.AuthWithOAuth2Ctx(context.Background(), providerName, urlCallback, scopes, createData, body, query, headers, expand, fields, timeout)
+}
+
+func (s *RecordService) AuthWithOAuth2Ctx(ctx context.Context, providerName string, urlCallback func(string), scopes []string, createData map[string]interface{}, body map[string]interface{}, query map[string]interface{}, headers map[string]string, expand, fields string, timeout time.Duration) (map[string]interface{}, error) {
+    provider, err := s.findOAuth2Provider(ctx, providerName)
     if err != nil {
         return nil, err
     }
-    providers := []interface{}{}
-    if oauth, ok := methods["oauth2"].(map[string]interface{}); ok {
-        if prov, ok := oauth["providers"].([]interface{}); ok {
-            providers = prov
-        }
-    }
-    var provider map[string]interface{}
-    for _, item := range providers {
-        if m, ok := item.(map[string]interface{}); ok {
-            if name, _ := m["name"].(string); name == providerName {
-                provider = m
-                break
-            }
-        }
-    }
-    if provider == nil {
-        return nil, &ClientResponseError{Response: map[string]interface{}{"message": fmt.Sprintf("missing provider %s", providerName)}}
-    }
 
     redirectURL := s.client.BuildURL("/api/oauth2-redirect", nil)
 
@@ -193,15 +202,15 @@ func (s *RecordService) AuthWithOAuth2(providerName string, urlCallback func(str
             return
         }
         if errMsg, ok := payload["error"].(string); ok && errMsg != "" {
-            errChan <- &ClientResponseError{Response: map[string]interface{}{"message": errMsg}}
+            errChan <- newClientError(redirectURL, 0, errMsg)
             return
         }
         code := fmt.Sprint(payload["code"])
         if code == "" {
-            errChan <- &ClientResponseError{Response: map[string]interface{}{"message": "OAuth2 redirect missing code"}}
+            errChan <- newClientError(redirectURL, 0, "OAuth2 redirect missing code")
             return
         }
-        auth, err := s.AuthWithOAuth2Code(providerName, code, fmt.Sprint(provider["codeVerifier"]), redirectURL, createData, body, query, headers, expand, fields)
+        auth, err := s.AuthWithOAuth2CodeCtx(ctx, providerName, code, fmt.Sprint(provider["codeVerifier"]), redirectURL, createData, body, query, headers, expand, fields)
         if err != nil {
             errChan <- err
             return
@@ -215,7 +224,7 @@ func (s *RecordService) AuthWithOAuth2(providerName string, urlCallback func(str
     }
     defer unsubscribe()
 
-    if err := s.client.Realtime.EnsureConnected(10 * time.Second); err != nil {
+    if err := s.client.Realtime.EnsureConnectedCtx(ctx, 10*time.Second); err != nil {
         return nil, err
     }
     state := s.client.Realtime.ClientID
@@ -238,12 +247,20 @@ func (s *RecordService) AuthWithOAuth2(providerName string, urlCallback func(str
         return res, nil
     case err := <-errChan:
         return nil, err
+    case <-ctx.Done():
+        return nil, newAbortError("", ctx.Err())
     case <-time.After(timeout):
-        return nil, &ClientResponseError{Response: map[string]interface{}{"message": "OAuth2 flow timed out"}}
+        err := newAbortError("", context.DeadlineExceeded)
+        err.Response = map[string]interface{}{"message": "OAuth2 flow timed out"}
+        return nil, err
     }
 }
 
 func (s *RecordService) AuthRefresh(expand, fields string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.AuthRefreshCtx(context.Background(), expand, fields, body, query, headers)
+}
+
+func (s *RecordService) AuthRefreshCtx(ctx context.Context, expand, fields string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     params := cloneQuery(query)
     if expand != "" {
         params["expand"] = expand
@@ -251,7 +268,7 @@ func (s *RecordService) AuthRefresh(expand, fields string, body map[string]inter
     if fields != "" {
         params["fields"] = fields
     }
-    data, err := s.client.Send(s.baseCollectionPath()+"/auth-refresh", &RequestOptions{Method: http.MethodPost, Body: body, Query: params, Headers: headers})
+    data, err := s.client.SendContext(ctx, s.baseCollectionPath()+"/auth-refresh", &RequestOptions{Method: http.MethodPost, Body: body, Query: params, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -262,16 +279,24 @@ func (s *RecordService) AuthRefresh(expand, fields string, body map[string]inter
 }
 
 func (s *RecordService) RequestPasswordReset(email string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+    return s.RequestPasswordResetCtx(context.Background(), email, body, query, headers)
+}
+
+func (s *RecordService) RequestPasswordResetCtx(ctx context.Context, email string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
     }
     payload["email"] = email
-    _, err := s.client.Send(s.baseCollectionPath()+"/request-password-reset", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
+    _, err := s.client.SendContext(ctx, s.baseCollectionPath()+"/request-password-reset", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
     return err
 }
 
 func (s *RecordService) ConfirmPasswordReset(token, password, passwordConfirm string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+    return s.ConfirmPasswordResetCtx(context.Background(), token, password, passwordConfirm, body, query, headers)
+}
+
+func (s *RecordService) ConfirmPasswordResetCtx(ctx context.Context, token, password, passwordConfirm string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
@@ -279,27 +304,35 @@ func (s *RecordService) ConfirmPasswordReset(token, password, passwordConfirm st
     payload["token"] = token
     payload["password"] = password
     payload["passwordConfirm"] = passwordConfirm
-    _, err := s.client.Send(s.baseCollectionPath()+"/confirm-password-reset", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
+    _, err := s.client.SendContext(ctx, s.baseCollectionPath()+"/confirm-password-reset", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
     return err
 }
 
 func (s *RecordService) RequestVerification(email string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+    return s.RequestVerificationCtx(context.Background(), email, body, query, headers)
+}
+
+func (s *RecordService) RequestVerificationCtx(ctx context.Context, email string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
     }
     payload["email"] = email
-    _, err := s.client.Send(s.baseCollectionPath()+"/request-verification", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
+    _, err := s.client.SendContext(ctx, s.baseCollectionPath()+"/request-verification", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
     return err
 }
 
 func (s *RecordService) ConfirmVerification(token string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+    return s.ConfirmVerificationCtx(context.Background(), token, body, query, headers)
+}
+
+func (s *RecordService) ConfirmVerificationCtx(ctx context.Context, token string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
     }
     payload["token"] = token
-    _, err := s.client.Send(s.baseCollectionPath()+"/confirm-verification", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
+    _, err := s.client.SendContext(ctx, s.baseCollectionPath()+"/confirm-verification", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
     if err == nil {
         s.markVerified(token)
     }
@@ -307,23 +340,31 @@ func (s *RecordService) ConfirmVerification(token string, body map[string]interf
 }
 
 func (s *RecordService) RequestEmailChange(newEmail string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+    return s.RequestEmailChangeCtx(context.Background(), newEmail, body, query, headers)
+}
+
+func (s *RecordService) RequestEmailChangeCtx(ctx context.Context, newEmail string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
     }
     payload["newEmail"] = newEmail
-    _, err := s.client.Send(s.baseCollectionPath()+"/request-email-change", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
+    _, err := s.client.SendContext(ctx, s.baseCollectionPath()+"/request-email-change", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
     return err
 }
 
 func (s *RecordService) ConfirmEmailChange(token, password string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+    return s.ConfirmEmailChangeCtx(context.Background(), token, password, body, query, headers)
+}
+
+func (s *RecordService) ConfirmEmailChangeCtx(ctx context.Context, token, password string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
     }
     payload["token"] = token
     payload["password"] = password
-    _, err := s.client.Send(s.baseCollectionPath()+"/confirm-email-change", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
+    _, err := s.client.SendContext(ctx, s.baseCollectionPath()+"/confirm-email-change", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
     if err == nil {
         s.clearIfSameToken(token)
     }
@@ -331,12 +372,16 @@ func (s *RecordService) ConfirmEmailChange(token, password string, body map[stri
 }
 
 func (s *RecordService) RequestOTP(email string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.RequestOTPCtx(context.Background(), email, body, query, headers)
+}
+
+func (s *RecordService) RequestOTPCtx(ctx context.Context, email string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
     }
     payload["email"] = email
-    data, err := s.client.Send(s.baseCollectionPath()+"/request-otp", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
+    data, err := s.client.SendContext(ctx, s.baseCollectionPath()+"/request-otp", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -347,6 +392,10 @@ func (s *RecordService) RequestOTP(email string, body map[string]interface{}, qu
 }
 
 func (s *RecordService) AuthWithOTP(otpID, password, expand, fields string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.AuthWithOTPCtx(context.Background(), otpID, password, expand, fields, body, query, headers)
+}
+
+func (s *RecordService) AuthWithOTPCtx(ctx context.Context, otpID, password, expand, fields string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
@@ -360,9 +409,9 @@ func (s *RecordService) AuthWithOTP(otpID, password, expand, fields string, body
     if fields != "" {
         params["fields"] = fields
     }
-    data, err := s.client.Send(s.baseCollectionPath()+"/auth-with-otp", &RequestOptions{Method: http.MethodPost, Body: payload, Query: params, Headers: headers})
+    data, err := s.client.SendContext(ctx, s.baseCollectionPath()+"/auth-with-otp", &RequestOptions{Method: http.MethodPost, Body: payload, Query: params, Headers: headers})
     if err != nil {
-        return nil, err
+        return nil, s.mfaChallenge(err, "otp")
     }
     if m, ok := data.(map[string]interface{}); ok {
         return s.authResponse(m), nil
@@ -372,6 +421,11 @@ func (s *RecordService) AuthWithOTP(otpID, password, expand, fields string, body
 
 // BindCustomToken binds a custom token to an auth record after verifying the email and password.
 func (s *RecordService) BindCustomToken(email, password, token string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+    return s.BindCustomTokenCtx(context.Background(), email, password, token, body, query, headers)
+}
+
+// BindCustomTokenCtx is like BindCustomToken but honors ctx for cancellation and deadlines.
+func (s *RecordService) BindCustomTokenCtx(ctx context.Context, email, password, token string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
@@ -379,12 +433,17 @@ func (s *RecordService) BindCustomToken(email, password, token string, body map[
     payload["email"] = email
     payload["password"] = password
     payload["token"] = token
-    _, err := s.client.Send(s.baseCollectionPath()+"/bind-token", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
+    _, err := s.client.SendContext(ctx, s.baseCollectionPath()+"/bind-token", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
     return err
 }
 
 // UnbindCustomToken removes a previously bound custom token after verifying the email and password.
 func (s *RecordService) UnbindCustomToken(email, password, token string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+    return s.UnbindCustomTokenCtx(context.Background(), email, password, token, body, query, headers)
+}
+
+// UnbindCustomTokenCtx is like UnbindCustomToken but honors ctx for cancellation and deadlines.
+func (s *RecordService) UnbindCustomTokenCtx(ctx context.Context, email, password, token string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
@@ -392,13 +451,18 @@ func (s *RecordService) UnbindCustomToken(email, password, token string, body ma
     payload["email"] = email
     payload["password"] = password
     payload["token"] = token
-    _, err := s.client.Send(s.baseCollectionPath()+"/unbind-token", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
+    _, err := s.client.SendContext(ctx, s.baseCollectionPath()+"/unbind-token", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
     return err
 }
 
 // AuthWithToken authenticates an auth collection record using a previously bound custom token.
 // On success, this method also automatically updates the client's AuthStore data.
 func (s *RecordService) AuthWithToken(token, expand, fields string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.AuthWithTokenCtx(context.Background(), token, expand, fields, body, query, headers)
+}
+
+// AuthWithTokenCtx is like AuthWithToken but honors ctx for cancellation and deadlines.
+func (s *RecordService) AuthWithTokenCtx(ctx context.Context, token, expand, fields string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
@@ -411,9 +475,9 @@ func (s *RecordService) AuthWithToken(token, expand, fields string, body map[str
     if fields != "" {
         params["fields"] = fields
     }
-    data, err := s.client.Send(s.baseCollectionPath()+"/auth-with-token", &RequestOptions{Method: http.MethodPost, Body: payload, Query: params, Headers: headers})
+    data, err := s.client.SendContext(ctx, s.baseCollectionPath()+"/auth-with-token", &RequestOptions{Method: http.MethodPost, Body: payload, Query: params, Headers: headers})
     if err != nil {
-        return nil, err
+        return nil, s.mfaChallenge(err, "token")
     }
     if m, ok := data.(map[string]interface{}); ok {
         return s.authResponse(m), nil
@@ -423,13 +487,18 @@ func (s *RecordService) AuthWithToken(token, expand, fields string, body map[str
 
 // ListExternalAuths lists all linked external auth providers for the specified auth record.
 func (s *RecordService) ListExternalAuths(recordID string, query map[string]interface{}, headers map[string]string) ([]map[string]interface{}, error) {
+    return s.ListExternalAuthsCtx(context.Background(), recordID, query, headers)
+}
+
+// ListExternalAuthsCtx is like ListExternalAuths but honors ctx for cancellation and deadlines.
+func (s *RecordService) ListExternalAuthsCtx(ctx context.Context, recordID string, query map[string]interface{}, headers map[string]string) ([]map[string]interface{}, error) {
     filter := s.client.Filter("recordRef = {:id}", map[string]interface{}{"id": recordID})
     params := cloneQuery(query)
     if params == nil {
         params = map[string]interface{}{}
     }
     params["filter"] = filter
-    data, err := s.client.Collection("_externalAuths").GetFullList(500, &CrudListOptions{
+    data, err := s.client.Collection("_externalAuths").GetFullListCtx(ctx, 500, &CrudListOptions{
         Filter:  filter,
         Query:   params,
         Headers: headers,
@@ -447,6 +516,10 @@ func (s *RecordService) ListExternalAuths(recordID string, query map[string]inte
 }
 
 func (s *RecordService) Impersonate(recordID string, duration int, expand, fields string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (*BosBase, error) {
+    return s.ImpersonateCtx(context.Background(), recordID, duration, expand, fields, body, query, headers)
+}
+
+func (s *RecordService) ImpersonateCtx(ctx context.Context, recordID string, duration int, expand, fields string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (*BosBase, error) {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
@@ -466,8 +539,10 @@ func (s *RecordService) Impersonate(recordID string, duration int, expand, field
         enrichedHeaders["Authorization"] = s.client.AuthStore.Token()
     }
 
-    newClient := New(s.client.BaseURL, WithLanguage(s.client.Lang))
-    data, err := newClient.Send(fmt.Sprintf("%s/impersonate/%s", s.baseCollectionPath(), encodePathSegment(recordID)), &RequestOptions{Method: http.MethodPost, Body: payload, Query: params, Headers: enrichedHeaders})
+    newClient := s.client.WithAuth("", nil)
+    // impersonate tokens cannot be refreshed
+    newClient.autoRefresh = nil
+    data, err := newClient.SendContext(ctx, fmt.Sprintf("%s/impersonate/%s", s.baseCollectionPath(), encodePathSegment(recordID)), &RequestOptions{Method: http.MethodPost, Body: payload, Query: params, Headers: enrichedHeaders})
     if err != nil {
         return nil, err
     }
@@ -533,11 +608,11 @@ func (s *RecordService) markVerified(token string) {
     if current == nil {
         return
     }
-    payload := decodeTokenPayload(token)
-    if payload == nil {
+    claims, err := ParseTokenClaims(token)
+    if err != nil {
         return
     }
-    if fmt.Sprint(current["id"]) == fmt.Sprint(payload["id"]) && fmt.Sprint(current["collectionId"]) == fmt.Sprint(payload["collectionId"]) {
+    if fmt.Sprint(current["id"]) == claims.ID && fmt.Sprint(current["collectionId"]) == claims.CollectionID {
         if verified, _ := current["verified"].(bool); !verified {
             current["verified"] = true
             s.client.AuthStore.Save(s.client.AuthStore.Token(), current)
@@ -550,32 +625,11 @@ func (s *RecordService) clearIfSameToken(token string) {
     if current == nil {
         return
     }
-    payload := decodeTokenPayload(token)
-    if payload == nil {
+    claims, err := ParseTokenClaims(token)
+    if err != nil {
         return
     }
-    if fmt.Sprint(current["id"]) == fmt.Sprint(payload["id"]) && fmt.Sprint(current["collectionId"]) == fmt.Sprint(payload["collectionId"]) {
+    if fmt.Sprint(current["id"]) == claims.ID && fmt.Sprint(current["collectionId"]) == claims.CollectionID {
         s.client.AuthStore.Clear()
     }
 }
-
-func decodeTokenPayload(token string) map[string]interface{} {
-    parts := strings.Split(token, ".")
-    if len(parts) != 3 {
-        return nil
-    }
-    payloadPart := parts[1]
-    padding := len(payloadPart) % 4
-    if padding > 0 {
-        payloadPart += strings.Repeat("=", 4-padding)
-    }
-    decoded, err := base64.URLEncoding.DecodeString(payloadPart)
-    if err != nil {
-        return nil
-    }
-    var payload map[string]interface{}
-    if err := json.Unmarshal(decoded, &payload); err != nil {
-        return nil
-    }
-    return payload
-}
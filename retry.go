@@ -0,0 +1,274 @@
+package bosbase
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures automatic retries of transient request failures.
+//
+// Only idempotent methods (GET, HEAD, OPTIONS, PUT, DELETE) are retried unless
+// RetryPOST is set or the request is marked with RequestOptions.Idempotent.
+// Multipart uploads are retried only when every FileParam.Reader implements
+// io.Seeker so the body can be rewound between attempts.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the exponential backoff delay.
+	MaxBackoff time.Duration
+	// MaxElapsed caps the total time spent on all attempts and waits (0 = no cap).
+	MaxElapsed time.Duration
+	// Jitter randomizes every delay by up to the given fraction (0 uses 0.2, <0 disables).
+	Jitter float64
+	// RetryStatuses lists HTTP statuses treated as transient.
+	RetryStatuses []int
+	// RetryPOST allows POST requests to be retried.
+	RetryPOST bool
+	// RecordAttempts stores the number of attempts on the returned ClientResponseError.
+	RecordAttempts bool
+}
+
+// DefaultRetryPolicy returns a policy with three attempts and exponential backoff.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{}.normalized()
+}
+
+// WithRetryPolicy enables automatic retries for transient failures.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *BosBase) {
+		p := policy.normalized()
+		c.retryPolicy = &p
+	}
+}
+
+func (p RetryPolicy) normalized() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = 200 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 10 * time.Second
+	}
+	if p.Jitter == 0 {
+		p.Jitter = 0.2
+	} else if p.Jitter < 0 {
+		p.Jitter = 0
+	} else if p.Jitter > 1 {
+		p.Jitter = 1
+	}
+	if p.RetryStatuses == nil {
+		p.RetryStatuses = []int{
+			http.StatusRequestTimeout,
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		}
+	}
+	return p
+}
+
+// allowsMethod reports whether the policy may retry requests with the given method.
+func (p *RetryPolicy) allowsMethod(method string) bool {
+	if p == nil {
+		return false
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		return p.RetryPOST
+	}
+	return false
+}
+
+func (p *RetryPolicy) retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var cre *ClientResponseError
+	if !errors.As(err, &cre) {
+		return false
+	}
+	if cre.Status == 0 {
+		// transport level failure (connection reset, per-attempt timeout, ...)
+		return isTransientNetError(cre.OriginalErr)
+	}
+	for _, status := range p.RetryStatuses {
+		if status == cre.Status {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransientNetError reports whether a transport error is worth retrying:
+// timeouts and failed or dropped connections. Certificate and TLS failures,
+// invalid URLs and errors encoding the request body are not.
+func isTransientNetError(err error) bool {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	if err == nil {
+		return false
+	}
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	if errors.As(err, &verifyErr) || errors.As(err, &recordErr) || errors.As(err, &alertErr) ||
+		errors.As(err, &authorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < retry && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		spread := float64(delay) * p.Jitter
+		delay = time.Duration(float64(delay) - spread + rand.Float64()*2*spread)
+	}
+	return delay
+}
+
+func (p *RetryPolicy) annotate(err error, attempts int) error {
+	if !p.RecordAttempts {
+		return err
+	}
+	var cre *ClientResponseError
+	if errors.As(err, &cre) {
+		cre.Attempts = attempts
+	}
+	return err
+}
+
+// sendWithRetry runs attempt until it succeeds or the retry policy gives up.
+func (c *BosBase) sendWithRetry(ctx context.Context, idempotent bool, files map[string]FileParam, attempt func() (*http.Response, interface{}, error)) (*http.Response, interface{}, error) {
+	policy := c.retryPolicy
+	if policy == nil || !idempotent {
+		return attempt()
+	}
+	offsets, rewindable := fileOffsets(files)
+	started := time.Now()
+	for n := 1; ; n++ {
+		resp, data, err := attempt()
+		if err == nil {
+			return resp, data, nil
+		}
+		if n >= policy.MaxAttempts || !rewindable || !policy.retryable(ctx, err) {
+			return resp, data, policy.annotate(err, n)
+		}
+
+		delay := policy.backoff(n)
+		if wait, ok := retryAfter(resp); ok {
+			delay = wait
+		}
+		if policy.MaxElapsed > 0 && time.Since(started)+delay > policy.MaxElapsed {
+			return resp, data, policy.annotate(err, n)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil, policy.annotate(newAbortError("", ctx.Err()), n)
+		case <-timer.C:
+		}
+
+		if err := rewindFiles(files, offsets); err != nil {
+			return resp, data, policy.annotate(err, n)
+		}
+	}
+}
+
+// retryAfter extracts the Retry-After delay of 429 and 503 responses.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			secs = 0
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		wait := time.Until(at)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// fileOffsets records the current position of every file reader so the
+// multipart body can be rebuilt for a retry. It returns false when any reader
+// cannot be rewound.
+func fileOffsets(files map[string]FileParam) (map[string]int64, bool) {
+	offsets := make(map[string]int64, len(files))
+	for field, file := range files {
+		if file.Reader == nil {
+			continue
+		}
+		seeker, ok := file.Reader.(io.Seeker)
+		if !ok {
+			return nil, false
+		}
+		pos, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, false
+		}
+		offsets[field] = pos
+	}
+	return offsets, true
+}
+
+func rewindFiles(files map[string]FileParam, offsets map[string]int64) error {
+	for field, pos := range offsets {
+		if _, err := files[field].Reader.(io.Seeker).Seek(pos, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package bosbase
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Jitter:         -1,
+	}.normalized()
+
+	cases := []struct {
+		retry int
+		want  time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{50, time.Second},
+	}
+	for _, tc := range cases {
+		if got := policy.backoff(tc.retry); got != tc.want {
+			t.Errorf("backoff(%d) = %v, want %v", tc.retry, got, tc.want)
+		}
+	}
+}
+
+func TestRetryPolicyBackoffJitter(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: time.Second, Jitter: 0.5}.normalized()
+	for i := 0; i < 100; i++ {
+		got := policy.backoff(1)
+		if got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Fatalf("backoff(1) = %v, want within [500ms, 1.5s]", got)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	past := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+
+	cases := []struct {
+		name   string
+		status int
+		header string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"seconds", http.StatusTooManyRequests, "3", 3 * time.Second, true},
+		{"zero", http.StatusServiceUnavailable, "0", 0, true},
+		{"negative", http.StatusTooManyRequests, "-5", 0, true},
+		{"past date", http.StatusServiceUnavailable, past, 0, true},
+		{"missing", http.StatusTooManyRequests, "", 0, false},
+		{"garbage", http.StatusTooManyRequests, "soon", 0, false},
+		{"other status", http.StatusBadGateway, "3", 0, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tc.status, Header: http.Header{}}
+			if tc.header != "" {
+				resp.Header.Set("Retry-After", tc.header)
+			}
+			got, ok := retryAfter(resp)
+			if ok != tc.wantOK || got != tc.want {
+				t.Errorf("retryAfter() = %v, %v; want %v, %v", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+
+	t.Run("future date", func(t *testing.T) {
+		resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+		resp.Header.Set("Retry-After", future)
+		got, ok := retryAfter(resp)
+		if !ok || got < 59*time.Minute || got > time.Hour {
+			t.Errorf("retryAfter() = %v, %v; want about 1h", got, ok)
+		}
+	})
+
+	if _, ok := retryAfter(nil); ok {
+		t.Error("retryAfter(nil) should not report a delay")
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsTransientNetError(t *testing.T) {
+	wrap := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://example.com", Err: err}
+	}
+	reset := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection reset", wrap(reset), true},
+		{"connection refused", wrap(refused), true},
+		{"timeout", wrap(timeoutError{}), true},
+		{"deadline exceeded", wrap(context.DeadlineExceeded), true},
+		{"unexpected eof", wrap(io.ErrUnexpectedEOF), true},
+		{"server closed connection", wrap(io.EOF), true},
+		{"unknown authority", wrap(x509.UnknownAuthorityError{}), false},
+		{"hostname mismatch", wrap(x509.HostnameError{Host: "example.com"}), false},
+		{"certificate verification", wrap(&tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}), false},
+		{"tls record header", wrap(tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}), false},
+		{"unsupported scheme", wrap(errors.New(`unsupported protocol scheme "ftp"`)), false},
+		{"body encoding", fmt.Errorf("json: unsupported type: chan int"), false},
+		{"nil", nil, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isTransientNetError(tc.err); got != tc.want {
+				t.Errorf("isTransientNetError(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSendRetriesTransientStatus(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"ok":true}`)
+	}))
+	defer server.Close()
+
+	client := New(server.URL, WithRetryPolicy(RetryPolicy{InitialBackoff: time.Millisecond}))
+	result, err := client.SendContext(context.Background(), "/api/test", &RequestOptions{Method: http.MethodGet})
+	if err != nil {
+		t.Fatalf("SendContext() error = %v", err)
+	}
+	if data, _ := result.(map[string]interface{}); data["ok"] != true {
+		t.Errorf("SendContext() = %v", result)
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("server received %d calls, want 3", got)
+	}
+}
+
+func TestSendDoesNotRetryPOSTByDefault(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := New(server.URL, WithRetryPolicy(RetryPolicy{InitialBackoff: time.Millisecond, RecordAttempts: true}))
+	_, err := client.SendContext(context.Background(), "/api/test", &RequestOptions{Method: http.MethodPost})
+	var cre *ClientResponseError
+	if !errors.As(err, &cre) || cre.Status != http.StatusServiceUnavailable {
+		t.Fatalf("SendContext() error = %v, want a 503 ClientResponseError", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("server received %d calls, want 1", got)
+	}
+}
@@ -1,10 +1,12 @@
 package bosbase
 
 import (
+    "context"
     "errors"
     "fmt"
     "net/http"
     "strings"
+    "sync"
 )
 
 // BaseService provides access to the shared client.
@@ -41,6 +43,8 @@ type CrudMutateOptions struct {
     Headers map[string]string
     Files   map[string]FileParam
     Body    interface{}
+    // OnProgress reports the bytes of the request body uploaded so far.
+    OnProgress ProgressFunc
 }
 
 // CrudDeleteOptions configures delete operations.
@@ -67,6 +71,11 @@ func (s *BaseCrudService) basePath() string {
 
 // GetFullList retrieves all records in batches.
 func (s *BaseCrudService) GetFullList(batch int, opts *CrudListOptions) ([]interface{}, error) {
+    return s.GetFullListCtx(context.Background(), batch, opts)
+}
+
+// GetFullListCtx is like GetFullList but honors ctx for cancellation and deadlines.
+func (s *BaseCrudService) GetFullListCtx(ctx context.Context, batch int, opts *CrudListOptions) ([]interface{}, error) {
     if batch <= 0 {
         return nil, errors.New("batch must be > 0")
     }
@@ -80,7 +89,7 @@ func (s *BaseCrudService) GetFullList(batch int, opts *CrudListOptions) ([]inter
         options.Page = page
         options.PerPage = batch
         options.SkipTotal = true
-        data, err := s.GetList(options)
+        data, err := s.GetListCtx(ctx, options)
         if err != nil {
             return nil, err
         }
@@ -98,8 +107,109 @@ func (s *BaseCrudService) GetFullList(batch int, opts *CrudListOptions) ([]inter
     return result, nil
 }
 
+// ParallelFullListOptions configures GetFullListParallel.
+type ParallelFullListOptions struct {
+    // Concurrency is the number of pages fetched at once (defaults to 4).
+    Concurrency int
+    // PageSize is the number of items per page (defaults to 500).
+    PageSize int
+}
+
+// GetFullListParallel retrieves all records like GetFullList, but fetches the
+// first page with totals and then the remaining pages concurrently. Items
+// keep the requested sort order. The first failing page cancels the others.
+func (s *BaseCrudService) GetFullListParallel(ctx context.Context, opts *CrudListOptions, parallel *ParallelFullListOptions) ([]interface{}, error) {
+    concurrency, pageSize := 4, 500
+    if parallel != nil {
+        if parallel.Concurrency > 0 {
+            concurrency = parallel.Concurrency
+        }
+        if parallel.PageSize > 0 {
+            pageSize = parallel.PageSize
+        }
+    }
+    base := CrudListOptions{}
+    if opts != nil {
+        base = *opts
+    }
+    base.PerPage = pageSize
+    base.SkipTotal = false
+
+    first := base
+    first.Page = 1
+    data, err := s.GetListCtx(ctx, &first)
+    if err != nil {
+        return nil, err
+    }
+    firstItems, _ := data["items"].([]interface{})
+    totalPages, _ := data["totalPages"].(float64)
+    if int(totalPages) <= 1 {
+        return firstItems, nil
+    }
+
+    ctx, cancel := context.WithCancel(ctx)
+    defer cancel()
+
+    pages := make([][]interface{}, int(totalPages))
+    pages[0] = firstItems
+    jobs := make(chan int)
+    var (
+        wg       sync.WaitGroup
+        errOnce  sync.Once
+        firstErr error
+    )
+    for w := 0; w < concurrency && w < len(pages)-1; w++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for page := range jobs {
+                pageOpts := base
+                pageOpts.Page = page + 1
+                pageOpts.SkipTotal = true
+                data, err := s.GetListCtx(ctx, &pageOpts)
+                if err != nil {
+                    errOnce.Do(func() {
+                        firstErr = err
+                        cancel()
+                    })
+                    continue
+                }
+                pages[page], _ = data["items"].([]interface{})
+            }
+        }()
+    }
+dispatch:
+    for page := 1; page < len(pages); page++ {
+        select {
+        case jobs <- page:
+        case <-ctx.Done():
+            break dispatch
+        }
+    }
+    close(jobs)
+    wg.Wait()
+
+    if firstErr != nil {
+        return nil, firstErr
+    }
+    if err := ctx.Err(); err != nil {
+        return nil, newAbortError(s.client.BuildURL(s.basePath(), nil), err)
+    }
+    total, _ := data["totalItems"].(float64)
+    result := make([]interface{}, 0, int(total))
+    for _, items := range pages {
+        result = append(result, items...)
+    }
+    return result, nil
+}
+
 // GetList retrieves a paginated list.
 func (s *BaseCrudService) GetList(opts *CrudListOptions) (map[string]interface{}, error) {
+    return s.GetListCtx(context.Background(), opts)
+}
+
+// GetListCtx is like GetList but honors ctx for cancellation and deadlines.
+func (s *BaseCrudService) GetListCtx(ctx context.Context, opts *CrudListOptions) (map[string]interface{}, error) {
     options := opts
     if options == nil {
         options = &CrudListOptions{}
@@ -129,7 +239,7 @@ func (s *BaseCrudService) GetList(opts *CrudListOptions) (map[string]interface{}
         params["fields"] = options.Fields
     }
 
-    data, err := s.client.Send(s.basePath(), &RequestOptions{
+    data, err := s.client.SendContext(ctx, s.basePath(), &RequestOptions{
         Method:  http.MethodGet,
         Query:   params,
         Headers: options.Headers,
@@ -145,16 +255,13 @@ func (s *BaseCrudService) GetList(opts *CrudListOptions) (map[string]interface{}
 
 // GetOne fetches a single record by id.
 func (s *BaseCrudService) GetOne(recordID string, opts *CrudViewOptions) (map[string]interface{}, error) {
+    return s.GetOneCtx(context.Background(), recordID, opts)
+}
+
+// GetOneCtx is like GetOne but honors ctx for cancellation and deadlines.
+func (s *BaseCrudService) GetOneCtx(ctx context.Context, recordID string, opts *CrudViewOptions) (map[string]interface{}, error) {
     if strings.TrimSpace(recordID) == "" {
-        return nil, &ClientResponseError{
-            URL:    s.client.BuildURL(fmt.Sprintf("%s/", s.basePath()), nil),
-            Status: 404,
-            Response: map[string]interface{}{
-                "code":    404,
-                "message": "Missing required record id.",
-                "data":    map[string]interface{}{},
-            },
-        }
+        return nil, newClientError(s.client.BuildURL(fmt.Sprintf("%s/", s.basePath()), nil), http.StatusNotFound, "Missing required record id.")
     }
     options := opts
     if options == nil {
@@ -168,7 +275,7 @@ func (s *BaseCrudService) GetOne(recordID string, opts *CrudViewOptions) (map[st
         params["fields"] = options.Fields
     }
     encoded := encodePathSegment(recordID)
-    data, err := s.client.Send(fmt.Sprintf("%s/%s", s.basePath(), encoded), &RequestOptions{
+    data, err := s.client.SendContext(ctx, fmt.Sprintf("%s/%s", s.basePath(), encoded), &RequestOptions{
         Method:  http.MethodGet,
         Query:   params,
         Headers: options.Headers,
@@ -184,6 +291,11 @@ func (s *BaseCrudService) GetOne(recordID string, opts *CrudViewOptions) (map[st
 
 // GetFirstListItem returns the first record matching the filter.
 func (s *BaseCrudService) GetFirstListItem(filter string, opts *CrudViewOptions) (map[string]interface{}, error) {
+    return s.GetFirstListItemCtx(context.Background(), filter, opts)
+}
+
+// GetFirstListItemCtx is like GetFirstListItem but honors ctx for cancellation and deadlines.
+func (s *BaseCrudService) GetFirstListItemCtx(ctx context.Context, filter string, opts *CrudViewOptions) (map[string]interface{}, error) {
     options := opts
     if options == nil {
         options = &CrudViewOptions{}
@@ -198,20 +310,13 @@ func (s *BaseCrudService) GetFirstListItem(filter string, opts *CrudViewOptions)
         Query:  options.Query,
         Headers: options.Headers,
     }
-    data, err := s.GetList(listOpts)
+    data, err := s.GetListCtx(ctx, listOpts)
     if err != nil {
         return nil, err
     }
     items, _ := data["items"].([]interface{})
     if len(items) == 0 {
-        return nil, &ClientResponseError{
-            Status: 404,
-            Response: map[string]interface{}{
-                "code":    404,
-                "message": "The requested resource wasn't found.",
-                "data":    map[string]interface{}{},
-            },
-        }
+        return nil, newClientError(s.client.BuildURL(s.basePath(), nil), http.StatusNotFound, "The requested resource wasn't found.")
     }
     if obj, ok := items[0].(map[string]interface{}); ok {
         return obj, nil
@@ -221,6 +326,11 @@ func (s *BaseCrudService) GetFirstListItem(filter string, opts *CrudViewOptions)
 
 // Create inserts a new record.
 func (s *BaseCrudService) Create(opts *CrudMutateOptions) (map[string]interface{}, error) {
+    return s.CreateCtx(context.Background(), opts)
+}
+
+// CreateCtx is like Create but honors ctx for cancellation and deadlines.
+func (s *BaseCrudService) CreateCtx(ctx context.Context, opts *CrudMutateOptions) (map[string]interface{}, error) {
     options := opts
     if options == nil {
         options = &CrudMutateOptions{}
@@ -233,12 +343,13 @@ func (s *BaseCrudService) Create(opts *CrudMutateOptions) (map[string]interface{
         params["fields"] = options.Fields
     }
 
-    data, err := s.client.Send(s.basePath(), &RequestOptions{
+    data, err := s.client.SendContext(ctx, s.basePath(), &RequestOptions{
         Method:  http.MethodPost,
         Body:    options.Body,
         Query:   params,
         Files:   options.Files,
         Headers: options.Headers,
+        OnProgress: options.OnProgress,
     })
     if err != nil {
         return nil, err
@@ -251,6 +362,11 @@ func (s *BaseCrudService) Create(opts *CrudMutateOptions) (map[string]interface{
 
 // Update modifies a record.
 func (s *BaseCrudService) Update(recordID string, opts *CrudMutateOptions) (map[string]interface{}, error) {
+    return s.UpdateCtx(context.Background(), recordID, opts)
+}
+
+// UpdateCtx is like Update but honors ctx for cancellation and deadlines.
+func (s *BaseCrudService) UpdateCtx(ctx context.Context, recordID string, opts *CrudMutateOptions) (map[string]interface{}, error) {
     options := opts
     if options == nil {
         options = &CrudMutateOptions{}
@@ -263,12 +379,13 @@ func (s *BaseCrudService) Update(recordID string, opts *CrudMutateOptions) (map[
         params["fields"] = options.Fields
     }
     encoded := encodePathSegment(recordID)
-    data, err := s.client.Send(fmt.Sprintf("%s/%s", s.basePath(), encoded), &RequestOptions{
+    data, err := s.client.SendContext(ctx, fmt.Sprintf("%s/%s", s.basePath(), encoded), &RequestOptions{
         Method:  http.MethodPatch,
         Body:    options.Body,
         Query:   params,
         Files:   options.Files,
         Headers: options.Headers,
+        OnProgress: options.OnProgress,
     })
     if err != nil {
         return nil, err
@@ -281,12 +398,17 @@ func (s *BaseCrudService) Update(recordID string, opts *CrudMutateOptions) (map[
 
 // Delete removes a record.
 func (s *BaseCrudService) Delete(recordID string, opts *CrudDeleteOptions) error {
+    return s.DeleteCtx(context.Background(), recordID, opts)
+}
+
+// DeleteCtx is like Delete but honors ctx for cancellation and deadlines.
+func (s *BaseCrudService) DeleteCtx(ctx context.Context, recordID string, opts *CrudDeleteOptions) error {
     options := opts
     if options == nil {
         options = &CrudDeleteOptions{}
     }
     encoded := encodePathSegment(recordID)
-    _, err := s.client.Send(fmt.Sprintf("%s/%s", s.basePath(), encoded), &RequestOptions{
+    _, err := s.client.SendContext(ctx, fmt.Sprintf("%s/%s", s.basePath(), encoded), &RequestOptions{
         Method:  http.MethodDelete,
         Body:    options.Body,
         Query:   options.Query,
@@ -0,0 +1,121 @@
+package bosbase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pagedServer lists total records as "r<n>", calling hook for every page
+// after the first one.
+func pagedServer(t *testing.T, total int, hook func(w http.ResponseWriter, r *http.Request, page int) bool) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		page, _ := strconv.Atoi(query.Get("page"))
+		perPage, _ := strconv.Atoi(query.Get("perPage"))
+		if page > 1 && hook != nil && !hook(w, r, page) {
+			return
+		}
+		items := []map[string]interface{}{}
+		for i := (page - 1) * perPage; i < page*perPage && i < total; i++ {
+			items = append(items, map[string]interface{}{"id": fmt.Sprintf("r%d", i)})
+		}
+		result := map[string]interface{}{"page": page, "perPage": perPage, "items": items}
+		if query.Get("skipTotal") == "" {
+			result["totalItems"] = total
+			result["totalPages"] = (total + perPage - 1) / perPage
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetFullListParallelKeepsPageOrder(t *testing.T) {
+	server := pagedServer(t, 28, func(w http.ResponseWriter, r *http.Request, page int) bool {
+		// later pages answer first
+		time.Sleep(time.Duration(10-page) * 5 * time.Millisecond)
+		return true
+	})
+	client := New(server.URL)
+
+	items, err := client.Collection("posts").GetFullListParallel(context.Background(), nil, &ParallelFullListOptions{PageSize: 3, Concurrency: 4})
+	if err != nil {
+		t.Fatalf("GetFullListParallel() error = %v", err)
+	}
+	if len(items) != 28 {
+		t.Fatalf("got %d items, want 28", len(items))
+	}
+	for i, item := range items {
+		if id := item.(map[string]interface{})["id"]; id != fmt.Sprintf("r%d", i) {
+			t.Fatalf("item %d = %v, want r%d", i, id, i)
+		}
+	}
+}
+
+func TestGetFullListParallelConcurrencyLimit(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := pagedServer(t, 40, func(w http.ResponseWriter, r *http.Request, page int) bool {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return true
+	})
+	client := New(server.URL)
+
+	items, err := client.Collection("posts").GetFullListParallel(context.Background(), nil, &ParallelFullListOptions{PageSize: 2, Concurrency: 3})
+	if err != nil {
+		t.Fatalf("GetFullListParallel() error = %v", err)
+	}
+	if len(items) != 40 {
+		t.Errorf("got %d items, want 40", len(items))
+	}
+	if got := atomic.LoadInt32(&maxInFlight); got > 3 || got < 2 {
+		t.Errorf("%d pages fetched at once, want at most 3 (and more than one)", got)
+	}
+}
+
+func TestGetFullListParallelCancelsOnError(t *testing.T) {
+	var requested int32
+	server := pagedServer(t, 100, func(w http.ResponseWriter, r *http.Request, page int) bool {
+		atomic.AddInt32(&requested, 1)
+		if page == 2 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":404,"message":"Missing page."}`))
+			return false
+		}
+		// the other pages hang until the request is canceled
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		return false
+	})
+	client := New(server.URL)
+
+	start := time.Now()
+	_, err := client.Collection("posts").GetFullListParallel(context.Background(), nil, &ParallelFullListOptions{PageSize: 1, Concurrency: 2})
+	if !IsNotFound(err) {
+		t.Fatalf("GetFullListParallel() error = %v, want the failing page error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("returned after %s, the remaining workers were not canceled", elapsed)
+	}
+	if got := atomic.LoadInt32(&requested); got > 4 {
+		t.Errorf("%d pages requested after the first error", got)
+	}
+}
@@ -1,6 +1,9 @@
 package bosbase
 
-import "net/http"
+import (
+    "context"
+    "net/http"
+)
 
 type SettingsService struct {
     BaseService
@@ -11,7 +14,11 @@ func NewSettingsService(client *BosBase) *SettingsService {
 }
 
 func (s *SettingsService) GetAll(query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
-    data, err := s.client.Send("/api/settings", &RequestOptions{Query: query, Headers: headers})
+    return s.GetAllCtx(context.Background(), query, headers)
+}
+
+func (s *SettingsService) GetAllCtx(ctx context.Context, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    data, err := s.client.SendContext(ctx, "/api/settings", &RequestOptions{Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -22,7 +29,11 @@ func (s *SettingsService) GetAll(query map[string]interface{}, headers map[strin
 }
 
 func (s *SettingsService) Update(body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
-    data, err := s.client.Send("/api/settings", &RequestOptions{Method: http.MethodPatch, Body: body, Query: query, Headers: headers})
+    return s.UpdateCtx(context.Background(), body, query, headers)
+}
+
+func (s *SettingsService) UpdateCtx(ctx context.Context, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    data, err := s.client.SendContext(ctx, "/api/settings", &RequestOptions{Method: http.MethodPatch, Body: body, Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -33,6 +44,10 @@ func (s *SettingsService) Update(body map[string]interface{}, query map[string]i
 }
 
 func (s *SettingsService) TestS3(filesystem string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+    return s.TestS3Ctx(context.Background(), filesystem, body, query, headers)
+}
+
+func (s *SettingsService) TestS3Ctx(ctx context.Context, filesystem string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
@@ -40,11 +55,15 @@ func (s *SettingsService) TestS3(filesystem string, body map[string]interface{},
     if _, ok := payload["filesystem"]; !ok {
         payload["filesystem"] = filesystem
     }
-    _, err := s.client.Send("/api/settings/test/s3", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
+    _, err := s.client.SendContext(ctx, "/api/settings/test/s3", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
     return err
 }
 
 func (s *SettingsService) TestEmail(toEmail, template string, collection string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+    return s.TestEmailCtx(context.Background(), toEmail, template, collection, body, query, headers)
+}
+
+func (s *SettingsService) TestEmailCtx(ctx context.Context, toEmail, template string, collection string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
@@ -54,11 +73,15 @@ func (s *SettingsService) TestEmail(toEmail, template string, collection string,
     if collection != "" {
         payload["collection"] = collection
     }
-    _, err := s.client.Send("/api/settings/test/email", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
+    _, err := s.client.SendContext(ctx, "/api/settings/test/email", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
     return err
 }
 
 func (s *SettingsService) GenerateAppleClientSecret(clientID, teamID, keyID, privateKey string, duration int, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.GenerateAppleClientSecretCtx(context.Background(), clientID, teamID, keyID, privateKey, duration, body, query, headers)
+}
+
+func (s *SettingsService) GenerateAppleClientSecretCtx(ctx context.Context, clientID, teamID, keyID, privateKey string, duration int, body map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     payload := cloneQuery(body)
     if payload == nil {
         payload = map[string]interface{}{}
@@ -68,7 +91,7 @@ func (s *SettingsService) GenerateAppleClientSecret(clientID, teamID, keyID, pri
     payload["keyId"] = keyID
     payload["privateKey"] = privateKey
     payload["duration"] = duration
-    data, err := s.client.Send("/api/settings/apple/generate-client-secret", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
+    data, err := s.client.SendContext(ctx, "/api/settings/apple/generate-client-secret", &RequestOptions{Method: http.MethodPost, Body: payload, Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -79,7 +102,11 @@ func (s *SettingsService) GenerateAppleClientSecret(clientID, teamID, keyID, pri
 }
 
 func (s *SettingsService) GetCategory(category string, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
-    settings, err := s.GetAll(query, headers)
+    return s.GetCategoryCtx(context.Background(), category, query, headers)
+}
+
+func (s *SettingsService) GetCategoryCtx(ctx context.Context, category string, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    settings, err := s.GetAllCtx(ctx, query, headers)
     if err != nil {
         return nil, err
     }
@@ -93,6 +120,10 @@ func (s *SettingsService) GetCategory(category string, query map[string]interfac
 }
 
 func (s *SettingsService) UpdateMeta(appName, appURL, senderName, senderAddress string, hideControls *bool, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.UpdateMetaCtx(context.Background(), appName, appURL, senderName, senderAddress, hideControls, query, headers)
+}
+
+func (s *SettingsService) UpdateMetaCtx(ctx context.Context, appName, appURL, senderName, senderAddress string, hideControls *bool, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     meta := map[string]interface{}{}
     if appName != "" {
         meta["appName"] = appName
@@ -109,11 +140,15 @@ func (s *SettingsService) UpdateMeta(appName, appURL, senderName, senderAddress
     if hideControls != nil {
         meta["hideControls"] = *hideControls
     }
-    return s.Update(map[string]interface{}{"meta": meta}, query, headers)
+    return s.UpdateCtx(ctx, map[string]interface{}{"meta": meta}, query, headers)
 }
 
 func (s *SettingsService) GetApplicationSettings(query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
-    settings, err := s.GetAll(query, headers)
+    return s.GetApplicationSettingsCtx(context.Background(), query, headers)
+}
+
+func (s *SettingsService) GetApplicationSettingsCtx(ctx context.Context, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    settings, err := s.GetAllCtx(ctx, query, headers)
     if err != nil {
         return nil, err
     }
@@ -126,6 +161,10 @@ func (s *SettingsService) GetApplicationSettings(query map[string]interface{}, h
 }
 
 func (s *SettingsService) UpdateApplicationSettings(meta, trustedProxy, rateLimits, batch map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.UpdateApplicationSettingsCtx(context.Background(), meta, trustedProxy, rateLimits, batch, query, headers)
+}
+
+func (s *SettingsService) UpdateApplicationSettingsCtx(ctx context.Context, meta, trustedProxy, rateLimits, batch map[string]interface{}, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     payload := map[string]interface{}{}
     if meta != nil {
         payload["meta"] = meta
@@ -139,5 +178,5 @@ func (s *SettingsService) UpdateApplicationSettings(meta, trustedProxy, rateLimi
     if batch != nil {
         payload["batch"] = batch
     }
-    return s.Update(payload, query, headers)
+    return s.UpdateCtx(ctx, payload, query, headers)
 }
@@ -1,6 +1,7 @@
 package bosbase
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strings"
@@ -19,12 +20,17 @@ func NewSQLService(client *BosBase) *SQLService {
 // Execute runs a SQL statement via the management API and returns the result.
 // Only superuser tokens are allowed to call this endpoint.
 func (s *SQLService) Execute(query string, queryParams map[string]interface{}, headers map[string]string) (SQLExecuteResponse, error) {
+	return s.ExecuteCtx(context.Background(), query, queryParams, headers)
+}
+
+// ExecuteCtx is like Execute but honors ctx for cancellation and deadlines.
+func (s *SQLService) ExecuteCtx(ctx context.Context, query string, queryParams map[string]interface{}, headers map[string]string) (SQLExecuteResponse, error) {
 	trimmed := strings.TrimSpace(query)
 	if trimmed == "" {
 		return SQLExecuteResponse{}, errors.New("query is required")
 	}
 	payload := map[string]interface{}{"query": trimmed}
-	data, err := s.client.Send("/api/sql/execute", &RequestOptions{
+	data, err := s.client.SendContext(ctx, "/api/sql/execute", &RequestOptions{
 		Method:  http.MethodPost,
 		Body:    payload,
 		Query:   queryParams,
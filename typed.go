@@ -0,0 +1,168 @@
+package bosbase
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// ListResult is a page of list results decoded into T.
+type ListResult[T any] struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"perPage"`
+	TotalItems int `json:"totalItems"`
+	TotalPages int `json:"totalPages"`
+	Items      []T `json:"items"`
+}
+
+// TypedRecordService wraps a RecordService and decodes every record into T
+// using its json tags.
+type TypedRecordService[T any] struct {
+	records *RecordService
+}
+
+// TypedCollection returns a typed view over the records of a collection.
+//
+//	type Post struct {
+//		ID    string `json:"id"`
+//		Title string `json:"title"`
+//	}
+//
+//	posts := bosbase.TypedCollection[Post](client, "posts")
+//	page, err := posts.GetList(ctx, &bosbase.CrudListOptions{PerPage: 20})
+func TypedCollection[T any](client *BosBase, collectionIDOrName string) *TypedRecordService[T] {
+	return &TypedRecordService[T]{records: client.Collection(collectionIDOrName)}
+}
+
+// Records returns the untyped RecordService backing s, e.g. for auth methods.
+func (s *TypedRecordService[T]) Records() *RecordService {
+	return s.records
+}
+
+// GetList retrieves a paginated list.
+func (s *TypedRecordService[T]) GetList(ctx context.Context, opts *CrudListOptions) (*ListResult[T], error) {
+	data, err := s.records.GetListCtx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	result := &ListResult[T]{}
+	if err := decodeInto(data, result); err != nil {
+		return nil, err
+	}
+	if result.Items == nil {
+		result.Items = []T{}
+	}
+	return result, nil
+}
+
+// GetFullList retrieves all records in batches.
+func (s *TypedRecordService[T]) GetFullList(ctx context.Context, batch int, opts *CrudListOptions) ([]T, error) {
+	items, err := s.records.GetFullListCtx(ctx, batch, opts)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]T, 0, len(items))
+	if err := decodeInto(items, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetOne fetches a single record by id.
+func (s *TypedRecordService[T]) GetOne(ctx context.Context, recordID string, opts *CrudViewOptions) (T, error) {
+	return decodeResult[T](s.records.GetOneCtx(ctx, recordID, opts))
+}
+
+// GetFirstListItem returns the first record matching the filter.
+func (s *TypedRecordService[T]) GetFirstListItem(ctx context.Context, filter string, opts *CrudViewOptions) (T, error) {
+	return decodeResult[T](s.records.GetFirstListItemCtx(ctx, filter, opts))
+}
+
+// Create inserts a new record. body may be a T, any other struct or a map;
+// it replaces opts.Body when non-nil.
+func (s *TypedRecordService[T]) Create(ctx context.Context, body interface{}, opts *CrudMutateOptions) (T, error) {
+	return decodeResult[T](s.records.CreateCtx(ctx, withBody(body, opts)))
+}
+
+// Update modifies a record. body may be a T, any other struct or a map;
+// it replaces opts.Body when non-nil. Use a map or omitempty tags to send
+// partial updates.
+func (s *TypedRecordService[T]) Update(ctx context.Context, recordID string, body interface{}, opts *CrudMutateOptions) (T, error) {
+	return decodeResult[T](s.records.UpdateCtx(ctx, recordID, withBody(body, opts)))
+}
+
+// Delete removes a record.
+func (s *TypedRecordService[T]) Delete(ctx context.Context, recordID string, opts *CrudDeleteOptions) error {
+	return s.records.DeleteCtx(ctx, recordID, opts)
+}
+
+// Call sends a request to path (typically a custom server route) and decodes
+// the JSON response into T. It is a function rather than a BosBase method
+// because Go methods cannot declare type parameters.
+//
+//	stats, err := bosbase.Call[Stats](ctx, client, "/api/stats", nil)
+func Call[T any](ctx context.Context, client *BosBase, path string, opts *RequestOptions) (T, error) {
+	var out T
+	data, err := client.SendContext(ctx, path, opts)
+	if err != nil {
+		return out, err
+	}
+	if err := decodeInto(data, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+func withBody(body interface{}, opts *CrudMutateOptions) *CrudMutateOptions {
+	options := CrudMutateOptions{}
+	if opts != nil {
+		options = *opts
+	}
+	if body != nil {
+		options.Body = body
+	}
+	return &options
+}
+
+func decodeResult[T any](data map[string]interface{}, err error) (T, error) {
+	var out T
+	if err != nil {
+		return out, err
+	}
+	if err := decodeInto(data, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// decodeInto converts a decoded JSON value into dst via its json tags.
+func decodeInto(data interface{}, dst interface{}) error {
+	if data == nil {
+		return nil
+	}
+	if raw, ok := data.([]byte); ok {
+		// non-JSON responses can be received as raw bytes or text
+		switch v := dst.(type) {
+		case *[]byte:
+			*v = raw
+			return nil
+		case *string:
+			*v = string(raw)
+			return nil
+		}
+		if len(raw) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(raw, dst); err != nil {
+			return &ClientResponseError{OriginalErr: err}
+		}
+		return nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return &ClientResponseError{OriginalErr: err}
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return &ClientResponseError{OriginalErr: err}
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package bosbase
 
-import "net/http"
+import (
+    "context"
+    "net/http"
+)
 
 type VectorService struct {
     BaseService
@@ -19,7 +22,11 @@ func (s *VectorService) collectionPath(collection string) string {
 }
 
 func (s *VectorService) Insert(doc VectorDocument, collection string, query map[string]interface{}, headers map[string]string) (VectorInsertResponse, error) {
-    data, err := s.client.Send(s.collectionPath(collection), &RequestOptions{Method: http.MethodPost, Body: doc.ToMap(), Query: query, Headers: headers})
+    return s.InsertCtx(context.Background(), doc, collection, query, headers)
+}
+
+func (s *VectorService) InsertCtx(ctx context.Context, doc VectorDocument, collection string, query map[string]interface{}, headers map[string]string) (VectorInsertResponse, error) {
+    data, err := s.client.SendContext(ctx, s.collectionPath(collection), &RequestOptions{Method: http.MethodPost, Body: doc.ToMap(), Query: query, Headers: headers})
     if err != nil {
         return VectorInsertResponse{}, err
     }
@@ -30,7 +37,11 @@ func (s *VectorService) Insert(doc VectorDocument, collection string, query map[
 }
 
 func (s *VectorService) BatchInsert(opts VectorBatchInsertOptions, collection string, query map[string]interface{}, headers map[string]string) (VectorBatchInsertResponse, error) {
-    data, err := s.client.Send(s.collectionPath(collection)+"/documents/batch", &RequestOptions{Method: http.MethodPost, Body: opts.ToMap(), Query: query, Headers: headers})
+    return s.BatchInsertCtx(context.Background(), opts, collection, query, headers)
+}
+
+func (s *VectorService) BatchInsertCtx(ctx context.Context, opts VectorBatchInsertOptions, collection string, query map[string]interface{}, headers map[string]string) (VectorBatchInsertResponse, error) {
+    data, err := s.client.SendContext(ctx, s.collectionPath(collection)+"/documents/batch", &RequestOptions{Method: http.MethodPost, Body: opts.ToMap(), Query: query, Headers: headers})
     if err != nil {
         return VectorBatchInsertResponse{}, err
     }
@@ -41,8 +52,12 @@ func (s *VectorService) BatchInsert(opts VectorBatchInsertOptions, collection st
 }
 
 func (s *VectorService) Update(documentID string, doc VectorDocument, collection string, query map[string]interface{}, headers map[string]string) (VectorInsertResponse, error) {
+    return s.UpdateCtx(context.Background(), documentID, doc, collection, query, headers)
+}
+
+func (s *VectorService) UpdateCtx(ctx context.Context, documentID string, doc VectorDocument, collection string, query map[string]interface{}, headers map[string]string) (VectorInsertResponse, error) {
     path := s.collectionPath(collection) + "/" + encodePathSegment(documentID)
-    data, err := s.client.Send(path, &RequestOptions{Method: http.MethodPatch, Body: doc.ToMap(), Query: query, Headers: headers})
+    data, err := s.client.SendContext(ctx, path, &RequestOptions{Method: http.MethodPatch, Body: doc.ToMap(), Query: query, Headers: headers})
     if err != nil {
         return VectorInsertResponse{}, err
     }
@@ -53,13 +68,21 @@ func (s *VectorService) Update(documentID string, doc VectorDocument, collection
 }
 
 func (s *VectorService) Delete(documentID string, collection string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+    return s.DeleteCtx(context.Background(), documentID, collection, body, query, headers)
+}
+
+func (s *VectorService) DeleteCtx(ctx context.Context, documentID string, collection string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
     path := s.collectionPath(collection) + "/" + encodePathSegment(documentID)
-    _, err := s.client.Send(path, &RequestOptions{Method: http.MethodDelete, Body: body, Query: query, Headers: headers})
+    _, err := s.client.SendContext(ctx, path, &RequestOptions{Method: http.MethodDelete, Body: body, Query: query, Headers: headers})
     return err
 }
 
 func (s *VectorService) Search(options VectorSearchOptions, collection string, query map[string]interface{}, headers map[string]string) (VectorSearchResponse, error) {
-    data, err := s.client.Send(s.collectionPath(collection)+"/documents/search", &RequestOptions{Method: http.MethodPost, Body: options.ToMap(), Query: query, Headers: headers})
+    return s.SearchCtx(context.Background(), options, collection, query, headers)
+}
+
+func (s *VectorService) SearchCtx(ctx context.Context, options VectorSearchOptions, collection string, query map[string]interface{}, headers map[string]string) (VectorSearchResponse, error) {
+    data, err := s.client.SendContext(ctx, s.collectionPath(collection)+"/documents/search", &RequestOptions{Method: http.MethodPost, Body: options.ToMap(), Query: query, Headers: headers})
     if err != nil {
         return VectorSearchResponse{}, err
     }
@@ -70,8 +93,12 @@ func (s *VectorService) Search(options VectorSearchOptions, collection string, q
 }
 
 func (s *VectorService) Get(documentID string, collection string, query map[string]interface{}, headers map[string]string) (VectorDocument, error) {
+    return s.GetCtx(context.Background(), documentID, collection, query, headers)
+}
+
+func (s *VectorService) GetCtx(ctx context.Context, documentID string, collection string, query map[string]interface{}, headers map[string]string) (VectorDocument, error) {
     path := s.collectionPath(collection) + "/" + encodePathSegment(documentID)
-    data, err := s.client.Send(path, &RequestOptions{Query: query, Headers: headers})
+    data, err := s.client.SendContext(ctx, path, &RequestOptions{Query: query, Headers: headers})
     if err != nil {
         return VectorDocument{}, err
     }
@@ -82,6 +109,10 @@ func (s *VectorService) Get(documentID string, collection string, query map[stri
 }
 
 func (s *VectorService) List(collection string, page *int, perPage *int, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
+    return s.ListCtx(context.Background(), collection, page, perPage, query, headers)
+}
+
+func (s *VectorService) ListCtx(ctx context.Context, collection string, page *int, perPage *int, query map[string]interface{}, headers map[string]string) (map[string]interface{}, error) {
     params := cloneQuery(query)
     if page != nil {
         params["page"] = *page
@@ -89,7 +120,7 @@ func (s *VectorService) List(collection string, page *int, perPage *int, query m
     if perPage != nil {
         params["perPage"] = *perPage
     }
-    data, err := s.client.Send(s.collectionPath(collection), &RequestOptions{Query: params, Headers: headers})
+    data, err := s.client.SendContext(ctx, s.collectionPath(collection), &RequestOptions{Query: params, Headers: headers})
     if err != nil {
         return nil, err
     }
@@ -100,25 +131,41 @@ func (s *VectorService) List(collection string, page *int, perPage *int, query m
 }
 
 func (s *VectorService) CreateCollection(name string, config VectorCollectionConfig, query map[string]interface{}, headers map[string]string) error {
+    return s.CreateCollectionCtx(context.Background(), name, config, query, headers)
+}
+
+func (s *VectorService) CreateCollectionCtx(ctx context.Context, name string, config VectorCollectionConfig, query map[string]interface{}, headers map[string]string) error {
     path := s.basePath + "/collections/" + encodePathSegment(name)
-    _, err := s.client.Send(path, &RequestOptions{Method: http.MethodPost, Body: config.ToMap(), Query: query, Headers: headers})
+    _, err := s.client.SendContext(ctx, path, &RequestOptions{Method: http.MethodPost, Body: config.ToMap(), Query: query, Headers: headers})
     return err
 }
 
 func (s *VectorService) UpdateCollection(name string, config VectorCollectionConfig, query map[string]interface{}, headers map[string]string) error {
+    return s.UpdateCollectionCtx(context.Background(), name, config, query, headers)
+}
+
+func (s *VectorService) UpdateCollectionCtx(ctx context.Context, name string, config VectorCollectionConfig, query map[string]interface{}, headers map[string]string) error {
     path := s.basePath + "/collections/" + encodePathSegment(name)
-    _, err := s.client.Send(path, &RequestOptions{Method: http.MethodPatch, Body: config.ToMap(), Query: query, Headers: headers})
+    _, err := s.client.SendContext(ctx, path, &RequestOptions{Method: http.MethodPatch, Body: config.ToMap(), Query: query, Headers: headers})
     return err
 }
 
 func (s *VectorService) DeleteCollection(name string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
+    return s.DeleteCollectionCtx(context.Background(), name, body, query, headers)
+}
+
+func (s *VectorService) DeleteCollectionCtx(ctx context.Context, name string, body map[string]interface{}, query map[string]interface{}, headers map[string]string) error {
     path := s.basePath + "/collections/" + encodePathSegment(name)
-    _, err := s.client.Send(path, &RequestOptions{Method: http.MethodDelete, Body: body, Query: query, Headers: headers})
+    _, err := s.client.SendContext(ctx, path, &RequestOptions{Method: http.MethodDelete, Body: body, Query: query, Headers: headers})
     return err
 }
 
 func (s *VectorService) ListCollections(query map[string]interface{}, headers map[string]string) ([]VectorCollectionInfo, error) {
-    data, err := s.client.Send(s.basePath+"/collections", &RequestOptions{Query: query, Headers: headers})
+    return s.ListCollectionsCtx(context.Background(), query, headers)
+}
+
+func (s *VectorService) ListCollectionsCtx(ctx context.Context, query map[string]interface{}, headers map[string]string) ([]VectorCollectionInfo, error) {
+    data, err := s.client.SendContext(ctx, s.basePath+"/collections", &RequestOptions{Query: query, Headers: headers})
     if err != nil {
         return nil, err
     }